@@ -0,0 +1,383 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// AssignmentBlockType defines the type of a block of an assignment.
+type AssignmentBlockType string
+
+// Constants representing the types of assignment blocks.
+const (
+	BlockHeading    AssignmentBlockType = "heading"    // A heading, with its Level and Text.
+	BlockParagraph  AssignmentBlockType = "paragraph"  // A paragraph, with its Text.
+	BlockCode       AssignmentBlockType = "code"       // A fenced code block, with its Language, Annotations and Text.
+	BlockQuote      AssignmentBlockType = "quote"      // A block quote, with its Children.
+	BlockAdmonition AssignmentBlockType = "admonition" // An admonition, with its Kind, Title and Children.
+	BlockBreak      AssignmentBlockType = "break"      // A thematic break.
+)
+
+// AssignmentBlock is a block of an assignment. The fields set depend on its
+// Type.
+type AssignmentBlock struct {
+	Type        AssignmentBlockType `json:"type"`                  // The type of the block.
+	Line        int                 `json:"line"`                  // The line the block starts on, from 1.
+	Level       int                 `json:"level,omitempty"`       // The level of a heading, from 1 to 6.
+	Text        string              `json:"text,omitempty"`        // The text of a heading or paragraph, or the contents of a code block.
+	Language    string              `json:"language,omitempty"`    // The language of a code block.
+	Annotations []string            `json:"annotations,omitempty"` // The annotations following the language of a code block, such as "run".
+	Kind        string              `json:"kind,omitempty"`        // The lower-case kind of an admonition, such as "note" or "warning".
+	Title       string              `json:"title,omitempty"`       // The title of an admonition, if any.
+	Children    []AssignmentBlock   `json:"children,omitempty"`    // The blocks of a quote or admonition.
+}
+
+// Run reports whether a code block is annotated to be run in a terminal when
+// clicked.
+func (b AssignmentBlock) Run() bool {
+	return slices.Contains(b.Annotations, "run")
+}
+
+// Copy reports whether a code block can be copied, which is the case unless
+// it is annotated with "nocopy".
+func (b AssignmentBlock) Copy() bool {
+	return b.Type == BlockCode && !slices.Contains(b.Annotations, "nocopy")
+}
+
+// AssignmentImage is an image of an assignment.
+type AssignmentImage struct {
+	Alt  string `json:"alt"`  // The alternative text of the image.
+	Src  string `json:"src"`  // The URL or path of the image.
+	Line int    `json:"line"` // The line of the image.
+}
+
+// AssignmentTab is a link or button of an assignment switching to a tab,
+// such as [button label="Shell"](tab-0).
+type AssignmentTab struct {
+	Label string `json:"label"` // The text of the link, or the label of the button.
+	Tab   string `json:"tab"`   // The tab, such as "tab-0".
+	Line  int    `json:"line"`  // The line of the link.
+}
+
+// AssignmentVariable is an unresolved variable placeholder of an assignment,
+// such as [[ Instruqt-Var key="TOKEN" hostname="server" ]].
+type AssignmentVariable struct {
+	Key      string `json:"key"`      // The key of the variable.
+	Hostname string `json:"hostname"` // The host the variable is set on.
+	Raw      string `json:"raw"`      // The placeholder, as written in the assignment.
+	Line     int    `json:"line"`     // The line of the placeholder.
+}
+
+// AssignmentProblem is a malformed construct found while parsing an
+// assignment.
+type AssignmentProblem struct {
+	Line    int    `json:"line"`    // The line of the construct.
+	Message string `json:"message"` // A description of the problem.
+}
+
+// AssignmentDocument is the structure of a challenge assignment, as parsed by
+// ParseAssignment.
+type AssignmentDocument struct {
+	Blocks    []AssignmentBlock    `json:"blocks"`              // The top-level blocks.
+	Images    []AssignmentImage    `json:"images,omitempty"`    // The images, in order.
+	Tabs      []AssignmentTab      `json:"tabs,omitempty"`      // The tab links, in order.
+	Variables []AssignmentVariable `json:"variables,omitempty"` // The variable placeholders, in order, including those in code blocks.
+	Problems  []AssignmentProblem  `json:"problems,omitempty"`  // The malformed constructs, in order.
+}
+
+var (
+	headingPattern     = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	fencePattern       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})\\s*([^`]*)$")
+	containerPattern   = regexp.MustCompile(`^ {0,3}:::\s*(\w+)\s*(.*)$`)
+	admonitionPattern  = regexp.MustCompile(`^\[!(\w+)\]\s*(.*)$`)
+	imagePattern       = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\s*\)`)
+	tabLinkPattern     = regexp.MustCompile(`\[([^\]]*)\]\(\s*(tab-\d+)\s*\)`)
+	variablePattern    = regexp.MustCompile(`\[\[\s*Instruqt-Var\b(.*?)\]\]`)
+	attributePattern   = regexp.MustCompile(`(\w+)\s*=\s*"([^"]*)"`)
+	shellLanguages     = []string{"bash", "sh", "shell", "zsh", "console", "terminal"}
+	thematicBreakChars = "-*_"
+)
+
+// ParseAssignment parses the markdown of a challenge assignment into
+// headings, paragraphs, fenced code blocks, quotes and admonitions, and lists
+// its images, tab links and unresolved variable placeholders. Admonitions are
+// written either as GitHub alerts ("> [!NOTE]") or as containers (":::note"
+// to ":::"), which may be nested. Parsing never fails: malformed constructs
+// are reported in Problems.
+//
+// Parameters:
+//   - markdown: The assignment, without front matter.
+//
+// Returns:
+//   - *AssignmentDocument: The structure of the assignment.
+func ParseAssignment(markdown string) *AssignmentDocument {
+	doc := &AssignmentDocument{}
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	doc.Blocks = doc.parseBlocks(lines, 1)
+	return doc
+}
+
+// CodeBlocks returns all code blocks, including those nested in quotes and
+// admonitions, in order.
+func (d *AssignmentDocument) CodeBlocks() []AssignmentBlock {
+	var blocks []AssignmentBlock
+	var walk func([]AssignmentBlock)
+	walk = func(bb []AssignmentBlock) {
+		for _, b := range bb {
+			if b.Type == BlockCode {
+				blocks = append(blocks, b)
+			}
+			walk(b.Children)
+		}
+	}
+	walk(d.Blocks)
+	return blocks
+}
+
+// Commands returns the code blocks learners are expected to run: those
+// annotated with "run", and copyable blocks in a shell language.
+func (d *AssignmentDocument) Commands() []AssignmentBlock {
+	var commands []AssignmentBlock
+	for _, b := range d.CodeBlocks() {
+		if b.Run() || (b.Copy() && slices.Contains(shellLanguages, b.Language)) {
+			commands = append(commands, b)
+		}
+	}
+	return commands
+}
+
+// parseBlocks parses lines into blocks, first being the number of the first
+// line.
+func (d *AssignmentDocument) parseBlocks(lines []string, first int) []AssignmentBlock {
+	var (
+		blocks    []AssignmentBlock
+		paragraph []string
+		paraLine  int
+	)
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, "\n")
+		d.scanInline(text, paraLine)
+		blocks = append(blocks, AssignmentBlock{Type: BlockParagraph, Line: paraLine, Text: text})
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line, n := lines[i], first+i
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			flush()
+			block := AssignmentBlock{Type: BlockCode, Line: n}
+			info := strings.Split(m[3], ",")
+			block.Language = strings.TrimSpace(info[0])
+			for _, annotation := range info[1:] {
+				if annotation = strings.TrimSpace(annotation); annotation != "" {
+					block.Annotations = append(block.Annotations, annotation)
+				}
+			}
+
+			var contents []string
+			closed := false
+			for i++; i < len(lines); i++ {
+				if isClosingFence(lines[i], m[2]) {
+					closed = true
+					break
+				}
+				contents = append(contents, strings.TrimPrefix(lines[i], m[1]))
+			}
+			if !closed {
+				d.Problems = append(d.Problems, AssignmentProblem{Line: n, Message: "unterminated code block"})
+			}
+			block.Text = strings.Join(contents, "\n")
+			d.scanVariables(block.Text, n+1)
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			d.scanInline(m[2], n)
+			blocks = append(blocks, AssignmentBlock{Type: BlockHeading, Line: n, Level: len(m[1]), Text: m[2]})
+			continue
+		}
+
+		if m := containerPattern.FindStringSubmatch(line); m != nil {
+			flush()
+			end := containerEnd(lines, i+1)
+			if end == len(lines) {
+				d.Problems = append(d.Problems, AssignmentProblem{Line: n, Message: "unterminated admonition"})
+			}
+			blocks = append(blocks, AssignmentBlock{
+				Type:     BlockAdmonition,
+				Line:     n,
+				Kind:     strings.ToLower(m[1]),
+				Title:    strings.TrimSpace(m[2]),
+				Children: d.parseBlocks(lines[i+1:end], n+1),
+			})
+			i = end
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				l := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(l, ">") {
+					break
+				}
+				l = strings.TrimPrefix(l, ">")
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+			}
+			i--
+
+			if m := admonitionPattern.FindStringSubmatch(strings.TrimSpace(quoted[0])); m != nil {
+				blocks = append(blocks, AssignmentBlock{
+					Type:     BlockAdmonition,
+					Line:     n,
+					Kind:     strings.ToLower(m[1]),
+					Title:    m[2],
+					Children: d.parseBlocks(quoted[1:], n+1),
+				})
+			} else {
+				blocks = append(blocks, AssignmentBlock{Type: BlockQuote, Line: n, Children: d.parseBlocks(quoted, n)})
+			}
+			continue
+		}
+
+		if isThematicBreak(trimmed) {
+			flush()
+			blocks = append(blocks, AssignmentBlock{Type: BlockBreak, Line: n})
+			continue
+		}
+
+		if len(paragraph) == 0 {
+			paraLine = n
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	flush()
+
+	return blocks
+}
+
+// scanInline lists the images, tab links and variables of text starting on
+// the given line.
+func (d *AssignmentDocument) scanInline(text string, line int) {
+	for _, m := range imagePattern.FindAllStringSubmatchIndex(text, -1) {
+		d.Images = append(d.Images, AssignmentImage{
+			Alt:  text[m[2]:m[3]],
+			Src:  text[m[4]:m[5]],
+			Line: line + strings.Count(text[:m[0]], "\n"),
+		})
+	}
+
+	for _, m := range tabLinkPattern.FindAllStringSubmatchIndex(text, -1) {
+		label := text[m[2]:m[3]]
+		if strings.HasPrefix(label, "button") {
+			label = attributes(label)["label"]
+		}
+		d.Tabs = append(d.Tabs, AssignmentTab{
+			Label: label,
+			Tab:   text[m[4]:m[5]],
+			Line:  line + strings.Count(text[:m[0]], "\n"),
+		})
+	}
+
+	d.scanVariables(text, line)
+}
+
+// scanVariables lists the variable placeholders of text starting on the
+// given line.
+func (d *AssignmentDocument) scanVariables(text string, line int) {
+	for _, m := range variablePattern.FindAllStringSubmatchIndex(text, -1) {
+		attrs := attributes(text[m[2]:m[3]])
+		v := AssignmentVariable{
+			Key:      attrs["key"],
+			Hostname: attrs["hostname"],
+			Raw:      text[m[0]:m[1]],
+			Line:     line + strings.Count(text[:m[0]], "\n"),
+		}
+		if v.Key == "" {
+			d.Problems = append(d.Problems, AssignmentProblem{Line: v.Line, Message: "variable placeholder without key"})
+			continue
+		}
+		d.Variables = append(d.Variables, v)
+	}
+}
+
+// attributes parses name="value" pairs.
+func attributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attributePattern.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = m[2]
+	}
+	return attrs
+}
+
+// containerEnd returns the index of the ":::" line closing a container whose
+// contents start at lines[start], or len(lines) if it is unterminated. Lines
+// in code blocks are skipped, and nested containers are closed first.
+func containerEnd(lines []string, start int) int {
+	var fence string
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		switch {
+		case fence != "":
+			if isClosingFence(lines[i], fence) {
+				fence = ""
+			}
+		case strings.TrimSpace(lines[i]) == ":::":
+			if depth == 0 {
+				return i
+			}
+			depth--
+		default:
+			if m := fencePattern.FindStringSubmatch(lines[i]); m != nil {
+				fence = m[2]
+			} else if containerPattern.MatchString(lines[i]) {
+				depth++
+			}
+		}
+	}
+	return len(lines)
+}
+
+// isClosingFence reports whether line closes a code block opened with fence.
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// isThematicBreak reports whether a trimmed line is a thematic break, made of
+// three or more identical "-", "*" or "_" characters, possibly spaced.
+func isThematicBreak(trimmed string) bool {
+	if !strings.ContainsAny(trimmed[:1], thematicBreakChars) {
+		return false
+	}
+	compact := strings.ReplaceAll(trimmed, " ", "")
+	return len(compact) >= 3 && strings.Trim(compact, trimmed[:1]) == ""
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAssignment = "# Install Cilium\n" +
+	"\n" +
+	"Open the [button label=\"Shell\"](tab-0) and install Cilium\n" +
+	"on [[ Instruqt-Var key=\"CLUSTER\" hostname=\"server\" ]]:\n" +
+	"\n" +
+	"```bash,run\n" +
+	"cilium install --version [[ Instruqt-Var key=\"VERSION\" hostname=\"server\" ]]\n" +
+	"```\n" +
+	"\n" +
+	"![Architecture](../assets/cilium.png \"Cilium\")\n" +
+	"\n" +
+	"> [!WARNING] Heads up\n" +
+	"> This takes a while. Check the [editor](tab-1).\n" +
+	"> ```,nocopy\n" +
+	"> Waiting for Cilium...\n" +
+	"> ```\n" +
+	"\n" +
+	"---\n" +
+	"\n" +
+	":::note\n" +
+	"```yaml\n" +
+	"kind: CiliumNetworkPolicy\n" +
+	"```\n" +
+	":::\n" +
+	"\n" +
+	"```sh\n" +
+	"cilium status\n" +
+	"```\n"
+
+func TestParseAssignment(t *testing.T) {
+	doc := ParseAssignment(testAssignment)
+
+	require.Len(t, doc.Blocks, 8)
+	assert.Equal(t, AssignmentBlock{Type: BlockHeading, Line: 1, Level: 1, Text: "Install Cilium"}, doc.Blocks[0])
+	assert.Equal(t, BlockParagraph, doc.Blocks[1].Type)
+	assert.Equal(t, 3, doc.Blocks[1].Line)
+
+	code := doc.Blocks[2]
+	assert.Equal(t, BlockCode, code.Type)
+	assert.Equal(t, "bash", code.Language)
+	assert.True(t, code.Run())
+	assert.True(t, code.Copy())
+	assert.Equal(t, "cilium install --version [[ Instruqt-Var key=\"VERSION\" hostname=\"server\" ]]", code.Text)
+
+	admonition := doc.Blocks[4]
+	assert.Equal(t, BlockAdmonition, admonition.Type)
+	assert.Equal(t, "warning", admonition.Kind)
+	assert.Equal(t, "Heads up", admonition.Title)
+	require.Len(t, admonition.Children, 2)
+	assert.Equal(t, 13, admonition.Children[0].Line)
+	assert.False(t, admonition.Children[1].Copy())
+
+	assert.Equal(t, BlockBreak, doc.Blocks[5].Type)
+	assert.Equal(t, "note", doc.Blocks[6].Kind)
+	assert.Equal(t, "yaml", doc.Blocks[6].Children[0].Language)
+
+	assert.Equal(t, []AssignmentImage{{Alt: "Architecture", Src: "../assets/cilium.png", Line: 10}}, doc.Images)
+	assert.Equal(t, []AssignmentTab{{Label: "Shell", Tab: "tab-0", Line: 3}, {Label: "editor", Tab: "tab-1", Line: 13}}, doc.Tabs)
+	assert.Equal(t, []AssignmentVariable{
+		{Key: "CLUSTER", Hostname: "server", Raw: `[[ Instruqt-Var key="CLUSTER" hostname="server" ]]`, Line: 4},
+		{Key: "VERSION", Hostname: "server", Raw: `[[ Instruqt-Var key="VERSION" hostname="server" ]]`, Line: 7},
+	}, doc.Variables)
+	assert.Empty(t, doc.Problems)
+}
+
+func TestAssignmentDocument_Commands(t *testing.T) {
+	doc := ParseAssignment(testAssignment)
+
+	assert.Len(t, doc.CodeBlocks(), 4)
+	commands := doc.Commands()
+	require.Len(t, commands, 2)
+	assert.Equal(t, 6, commands[0].Line)
+	assert.Equal(t, "cilium status", commands[1].Text)
+}
+
+func TestParseAssignment_Problems(t *testing.T) {
+	doc := ParseAssignment("Set [[ Instruqt-Var hostname=\"server\" ]].\n\n:::tip\nNo end\n\n```bash\nunterminated\n")
+
+	assert.Equal(t, []AssignmentProblem{
+		{Line: 1, Message: "variable placeholder without key"},
+		{Line: 3, Message: "unterminated admonition"},
+		{Line: 6, Message: "unterminated code block"},
+	}, doc.Problems)
+	assert.Empty(t, doc.Variables)
+}
+
+func TestParseAssignment_Containers(t *testing.T) {
+	doc := ParseAssignment(":::note\n" +
+		"```markdown\n" +
+		":::\n" +
+		"```\n" +
+		":::\n" +
+		"\n" +
+		":::tabs\n" +
+		":::tab Cilium\n" +
+		"Install Cilium.\n" +
+		":::\n" +
+		":::tab Tetragon\n" +
+		"Install Tetragon.\n" +
+		":::\n" +
+		":::\n" +
+		"\n" +
+		"Done.\n")
+
+	require.Len(t, doc.Blocks, 3)
+	note := doc.Blocks[0]
+	assert.Equal(t, "note", note.Kind)
+	require.Len(t, note.Children, 1)
+	assert.Equal(t, ":::", note.Children[0].Text, "Expected a fenced \":::\" not to close the container")
+
+	tabs := doc.Blocks[1]
+	assert.Equal(t, "tabs", tabs.Kind)
+	assert.Equal(t, 7, tabs.Line)
+	require.Len(t, tabs.Children, 2)
+	assert.Equal(t, "tab", tabs.Children[0].Kind)
+	assert.Equal(t, "Cilium", tabs.Children[0].Title)
+	assert.Equal(t, "Tetragon", tabs.Children[1].Title)
+	assert.Equal(t, 12, tabs.Children[1].Children[0].Line)
+
+	assert.Equal(t, AssignmentBlock{Type: BlockParagraph, Line: 16, Text: "Done."}, doc.Blocks[2])
+	assert.Empty(t, doc.Problems)
+}
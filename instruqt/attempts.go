@@ -0,0 +1,295 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FailureMessage is a check failure message shared by several attempts once
+// normalized.
+type FailureMessage struct {
+	Message string `json:"message"` // The normalized message, see NormalizeFailureMessage.
+	Example string `json:"example"` // The first message seen with this normalized form.
+	Count   int    `json:"count"`   // The number of failed attempts with the message.
+	Players int    `json:"players"` // The number of players who got the message.
+}
+
+// ChallengeAttemptStats aggregates the checks of a challenge by the players
+// of a track.
+type ChallengeAttemptStats struct {
+	ChallengeId   string `json:"challenge_id"`   // The unique identifier of the challenge.
+	ChallengeSlug string `json:"challenge_slug"` // The slug of the challenge.
+	Title         string `json:"title"`          // The title of the challenge.
+	Index         int    `json:"index"`          // The index of the challenge in the track.
+
+	Players             int `json:"players"`               // The number of players who reached the challenge.
+	Completed           int `json:"completed"`             // The number of players who completed the challenge.
+	Attempts            int `json:"attempts"`              // The number of checks run.
+	FailedAttempts      int `json:"failed_attempts"`       // The number of checks that failed.
+	PlayersWithFailures int `json:"players_with_failures"` // The number of players with at least one failed check.
+	FirstTrySuccesses   int `json:"first_try_successes"`   // The number of players whose first check passed.
+
+	// DropOffs is the number of players whose last check failed and who did
+	// not complete the challenge, having given up after a failed check.
+	DropOffs int `json:"drop_offs"`
+	// MedianTimeFromFirstCheckToSuccess is the median time between the first
+	// check of a player and their first passed check, among players whose
+	// first check failed and who later passed a check.
+	MedianTimeFromFirstCheckToSuccess time.Duration `json:"median_time_from_first_check_to_success"`
+	// FailureMessages lists the normalized failure messages, most common first.
+	FailureMessages []FailureMessage `json:"failure_messages,omitempty"`
+}
+
+// DropOffRate returns the share of the players with a failed check who gave
+// up after it, between 0 and 1.
+func (s *ChallengeAttemptStats) DropOffRate() float64 {
+	if s.PlayersWithFailures == 0 {
+		return 0
+	}
+	return float64(s.DropOffs) / float64(s.PlayersWithFailures)
+}
+
+// AttemptAnalytics holds the attempt statistics of the challenges of a track.
+type AttemptAnalytics struct {
+	TrackId    string                  `json:"track_id"`   // The unique identifier of the track.
+	From       time.Time               `json:"from"`       // The start of the time window.
+	To         time.Time               `json:"to"`         // The end of the time window.
+	Players    int                     `json:"players"`    // The number of distinct players of the track in the window.
+	Challenges []ChallengeAttemptStats `json:"challenges"` // The statistics of each challenge, in track order.
+}
+
+// Patterns replaced by NormalizeFailureMessage, in order.
+var failureMessageReplacements = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`), ""},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?(/\d{1,2})?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b(0x[0-9a-f]+|[0-9a-f]{7,})\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(\.\d+)*`), "<n>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// NormalizeFailureMessage normalizes a check failure message so that
+// messages differing only by generated values are counted together. ANSI
+// escape sequences are removed, UUIDs, timestamps, IP addresses, hexadecimal
+// hashes and numbers are replaced with placeholders, and whitespace is
+// collapsed.
+//
+// Parameters:
+//   - message: The message of a failed check.
+//
+// Returns:
+//   - string: The normalized message.
+func NormalizeFailureMessage(message string) string {
+	for _, r := range failureMessageReplacements {
+		message = r.pattern.ReplaceAllString(message, r.replacement)
+	}
+	return strings.TrimSpace(message)
+}
+
+// AnalyzeChallengeAttempts aggregates the checks of the challenges of a
+// track by its players, to tell which check scripts confuse learners. The
+// players are the users of the plays of the track started in the time
+// window, and only their checks made between their first play in the window
+// and the end of the window are counted. Their challenges are retrieved with batched queries.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track.
+//   - from: The start of the time window.
+//   - to: The end of the time window.
+//   - opts: Optional settings, such as the filters supported by GetPlays,
+//     WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - *AttemptAnalytics: The statistics of the challenges. With
+//     ErrorPolicyCollectAll, they are returned along with the error, leaving
+//     out the challenges that could not be retrieved.
+//   - error: Any error encountered while retrieving the plays or challenges.
+func (c *Client) AnalyzeChallengeAttempts(trackId string, from time.Time, to time.Time, opts ...Option) (*AttemptAnalytics, error) {
+	return c.AnalyzeChallengeAttemptsCtx(c.context(), trackId, from, to, opts...)
+}
+
+// AnalyzeChallengeAttemptsCtx is like AnalyzeChallengeAttempts but uses the given context instead of the client's Context.
+func (c *Client) AnalyzeChallengeAttemptsCtx(ctx context.Context, trackId string, from time.Time, to time.Time, opts ...Option) (analytics *AttemptAnalytics, err error) {
+	ctx, end := c.startOperation(ctx, "AnalyzeChallengeAttempts", attribute.String("instruqt.trackId", trackId))
+	defer func() { end(err) }()
+
+	if trackId == "" {
+		return nil, fmt.Errorf("%w: missing track ID", ErrValidation)
+	}
+
+	challenges, err := c.GetChallengesCtx(ctx, trackId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenges: %w", err)
+	}
+	slices.SortFunc(challenges, func(a, b Challenge) int { return a.Index - b.Index })
+
+	// The players, and the start of their first play in the window.
+	var players []string
+	since := make(map[string]time.Time)
+	for play, err := range c.AllPlaysCtx(ctx, from, to, append(slices.Clone(opts), WithTrackIDs(trackId))...) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plays: %w", err)
+		}
+		user := play.User.Id
+		if user == "" {
+			continue
+		}
+		if started, ok := since[user]; !ok {
+			players = append(players, user)
+			since[user] = play.StartedAt
+		} else if play.StartedAt.Before(started) {
+			since[user] = play.StartedAt
+		}
+	}
+
+	// Look up the challenges of every player, challenge by challenge.
+	n := len(challenges)
+	userChallenges, errs, err := batchLookup[Challenge](ctx, c, "AnalyzeChallengeAttempts", len(players)*n, func(i int, k int) (string, map[string]any) {
+		return fmt.Sprintf("challenge(userID: $user%d, challengeID: $challenge%d)", k, k), map[string]any{
+			fmt.Sprintf("user%d", k):      graphql.String(players[i/n]),
+			fmt.Sprintf("challenge%d", k): graphql.String(challenges[i%n].Id),
+		}
+	}, nil, batchOptions(opts))
+	if userChallenges == nil {
+		return nil, fmt.Errorf("failed to get user challenges: %w", err)
+	}
+
+	analytics = &AttemptAnalytics{
+		TrackId:    trackId,
+		From:       from,
+		To:         to,
+		Players:    len(players),
+		Challenges: make([]ChallengeAttemptStats, n),
+	}
+	for j, ch := range challenges {
+		var played []Challenge
+		for p, user := range players {
+			i := p*n + j
+			if errs[i] != nil {
+				continue
+			}
+			uc := userChallenges[i]
+			uc.Attempts = slices.DeleteFunc(slices.Clone(uc.Attempts), func(a ChallengeAttempt) bool {
+				return a.Timestamp.Before(since[user]) || !to.IsZero() && a.Timestamp.After(to)
+			})
+			played = append(played, uc)
+		}
+		analytics.Challenges[j] = challengeAttemptStats(ch, played)
+	}
+	if err != nil {
+		return analytics, fmt.Errorf("failed to get user challenges: %w", err)
+	}
+	return analytics, nil
+}
+
+// challengeAttemptStats aggregates the views of a challenge by its players.
+func challengeAttemptStats(ch Challenge, played []Challenge) ChallengeAttemptStats {
+	stats := ChallengeAttemptStats{
+		ChallengeId:   ch.Id,
+		ChallengeSlug: ch.Slug,
+		Title:         ch.Title,
+		Index:         ch.Index,
+	}
+
+	var (
+		timesToSuccess []time.Duration
+		messages       = make(map[string]*FailureMessage)
+	)
+	for _, uc := range played {
+		completed := uc.Status == "completed"
+		if !completed && len(uc.Attempts) == 0 && (uc.Status == "" || uc.Status == "locked") {
+			continue
+		}
+		stats.Players++
+		if completed {
+			stats.Completed++
+		}
+
+		attempts := slices.Clone(uc.Attempts)
+		slices.SortStableFunc(attempts, func(a, b ChallengeAttempt) int { return a.Timestamp.Compare(b.Timestamp) })
+
+		failed := false
+		succeeded := false
+		seen := make(map[string]bool)
+		for i, a := range attempts {
+			stats.Attempts++
+			if a.Message == "" {
+				if !succeeded {
+					succeeded = true
+					if i == 0 {
+						stats.FirstTrySuccesses++
+					} else {
+						timesToSuccess = append(timesToSuccess, a.Timestamp.Sub(attempts[0].Timestamp))
+					}
+				}
+				continue
+			}
+
+			stats.FailedAttempts++
+			failed = true
+			normalized := NormalizeFailureMessage(a.Message)
+			m, ok := messages[normalized]
+			if !ok {
+				m = &FailureMessage{Message: normalized, Example: a.Message}
+				messages[normalized] = m
+			}
+			m.Count++
+			if !seen[normalized] {
+				seen[normalized] = true
+				m.Players++
+			}
+		}
+
+		if failed {
+			stats.PlayersWithFailures++
+			if !completed && attempts[len(attempts)-1].Message != "" {
+				stats.DropOffs++
+			}
+		}
+	}
+
+	if len(timesToSuccess) > 0 {
+		slices.Sort(timesToSuccess)
+		mid := len(timesToSuccess) / 2
+		stats.MedianTimeFromFirstCheckToSuccess = timesToSuccess[mid]
+		if len(timesToSuccess)%2 == 0 {
+			stats.MedianTimeFromFirstCheckToSuccess = (timesToSuccess[mid-1] + timesToSuccess[mid]) / 2
+		}
+	}
+
+	for _, m := range messages {
+		stats.FailureMessages = append(stats.FailureMessages, *m)
+	}
+	slices.SortFunc(stats.FailureMessages, func(a, b FailureMessage) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Message, b.Message)
+	})
+	return stats
+}
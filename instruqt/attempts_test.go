@@ -0,0 +1,81 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeFailureMessage(t *testing.T) {
+	tests := map[string]struct {
+		message  string
+		expected string
+	}{
+		"plain":      {"Cilium is not installed", "Cilium is not installed"},
+		"ansi":       {"\x1b[31mFAIL\x1b[0m: no flows", "FAIL: no flows"},
+		"numbers":    {"expected 3 replicas, got  1\n", "expected <n> replicas, got <n>"},
+		"version":    {"cilium v1.16.0 is required", "cilium v<n> is required"},
+		"uuid":       {"sandbox 3f2b9c1e-8a4d-4c2e-9b1a-0d5e6f7a8b9c not ready", "sandbox <uuid> not ready"},
+		"ip":         {"cannot reach 10.0.1.23:8080", "cannot reach <ip>"},
+		"hash":       {"image sha256:9f86d081884c7d65 not found", "image sha<n>:<hex> not found"},
+		"timestamp":  {"last seen 2024-06-01T10:00:00Z", "last seen <time>"},
+		"hex prefix": {"exit code 0x1f", "exit code <hex>"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeFailureMessage(tt.message))
+		})
+	}
+}
+
+func TestChallengeAttemptStats(t *testing.T) {
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int, message string) ChallengeAttempt {
+		return ChallengeAttempt{Message: message, Timestamp: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	ch := Challenge{Id: "ch-1", Slug: "install", Title: "Install", Index: 0}
+	played := []Challenge{
+		// Passed at the first check.
+		{Status: "completed", Attempts: []ChallengeAttempt{at(0, "")}},
+		// Passed after two failed checks, 4 minutes after the first one.
+		{Status: "completed", Attempts: []ChallengeAttempt{at(4, ""), at(0, "expected 3 pods, got 1"), at(2, "expected 3 pods, got 2")}},
+		// Gave up after a failed check.
+		{Status: "started", Attempts: []ChallengeAttempt{at(0, "cilium is not installed"), at(1, "expected 3 pods, got 0")}},
+		// Never reached the challenge.
+		{Status: "locked"},
+	}
+
+	stats := challengeAttemptStats(ch, played)
+
+	assert.Equal(t, "install", stats.ChallengeSlug)
+	assert.Equal(t, 3, stats.Players)
+	assert.Equal(t, 2, stats.Completed)
+	assert.Equal(t, 6, stats.Attempts)
+	assert.Equal(t, 4, stats.FailedAttempts)
+	assert.Equal(t, 2, stats.PlayersWithFailures)
+	assert.Equal(t, 1, stats.FirstTrySuccesses)
+	assert.Equal(t, 1, stats.DropOffs)
+	assert.Equal(t, 0.5, stats.DropOffRate())
+	assert.Equal(t, 4*time.Minute, stats.MedianTimeFromFirstCheckToSuccess)
+	assert.Equal(t, []FailureMessage{
+		{Message: "expected <n> pods, got <n>", Example: "expected 3 pods, got 1", Count: 3, Players: 2},
+		{Message: "cilium is not installed", Example: "cilium is not installed", Count: 1, Players: 1},
+	}, stats.FailureMessages)
+}
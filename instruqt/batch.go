@@ -0,0 +1,277 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	graphql "github.com/hasura/go-graphql-client"
+)
+
+// defaultBatchSize is the number of lookups aliased into a single query by
+// the Batch methods, unless overridden with WithBatchSize.
+const defaultBatchSize = 50
+
+// batchQuery looks up one T per ID using aliased queries, such as
+// "c0: challenge(challengeID: $id0) c1: challenge(challengeID: $id1)".
+// The IDs are split in chunks of the configured batch size, which run in
+// parallel according to the concurrency and error policy options.
+//
+// Parameters:
+//   - operation: The name of the client operation, used for logs and traces.
+//   - field: The GraphQL field to look up, in which %s stands for the ID variable.
+//   - ids: The IDs to look up.
+//   - idValue: Converts an ID into its GraphQL variable value.
+//   - shared: Variables shared by every lookup, such as the team slug.
+//
+// Returns:
+//   - []T: The results, in the same order as ids. With ErrorPolicyCollectAll,
+//     the results of failed chunks are left empty.
+//   - error: Any error encountered while executing the queries.
+func batchQuery[T any](ctx context.Context, c *Client, operation string, field string, ids []string, idValue func(string) any, shared map[string]any, options *options) ([]T, error) {
+	results, _, err := batchQueryEach[T](ctx, c, operation, field, ids, idValue, shared, options)
+	return results, err
+}
+
+// batchQueryEach is like batchQuery but also returns the error of each
+// lookup, in the same order as ids. Lookups sharing a failed chunk share
+// its error.
+func batchQueryEach[T any](ctx context.Context, c *Client, operation string, field string, ids []string, idValue func(string) any, shared map[string]any, options *options) ([]T, []error, error) {
+	return batchLookup[T](ctx, c, operation, len(ids), func(i int, n int) (string, map[string]any) {
+		return fmt.Sprintf(field, fmt.Sprintf("$id%d", n)), map[string]any{fmt.Sprintf("id%d", n): idValue(ids[i])}
+	}, shared, options)
+}
+
+// batchLookup looks up count T using aliased queries. For the i-th lookup,
+// aliased as the n-th field of its chunk, lookup returns the GraphQL field
+// and the variables it uses, whose names must be suffixed with n so that
+// they are unique within the chunk.
+func batchLookup[T any](ctx context.Context, c *Client, operation string, count int, lookup func(i int, n int) (string, map[string]any), shared map[string]any, options *options) ([]T, []error, error) {
+	size := options.batchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	results := make([]T, count)
+	errs := make([]error, count)
+	chunks := (count + size - 1) / size
+	err := fanOut(ctx, chunks, options.concurrency, options.errorPolicy, func(ctx context.Context, chunk int) error {
+		start := chunk * size
+		end := min(start+size, count)
+
+		fields := make([]reflect.StructField, 0, end-start)
+		variables := make(map[string]any, len(shared)+end-start)
+		for k, v := range shared {
+			variables[k] = v
+		}
+		for i := start; i < end; i++ {
+			n := i - start
+			field, vars := lookup(i, n)
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("C%d", n),
+				Type: reflect.TypeFor[T](),
+				Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"c%d: %s"`, n, field)),
+			})
+			for k, v := range vars {
+				variables[k] = v
+			}
+		}
+
+		q := reflect.New(reflect.StructOf(fields))
+		if err := c.query(ctx, operation, q.Interface(), variables); err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = err
+			}
+			return err
+		}
+		for i := start; i < end; i++ {
+			results[i] = q.Elem().Field(i - start).Interface().(T)
+		}
+		return nil
+	})
+	if err != nil && options.errorPolicy == ErrorPolicyFailFast {
+		return nil, nil, err
+	}
+	return results, errs, err
+}
+
+// graphqlString converts an ID into a String! variable.
+func graphqlString(id string) any {
+	return graphql.String(id)
+}
+
+// graphqlID converts an ID into an ID! variable.
+func graphqlID(id string) any {
+	return graphql.ID(id)
+}
+
+// batchOptions applies opts to the default options of the Batch methods.
+func batchOptions(opts []Option) *options {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// BatchGetChallenges retrieves several challenges using their unique IDs,
+// aliasing up to WithBatchSize lookups into each GraphQL query.
+//
+// Parameters:
+//   - ids: The unique identifiers of the challenges to retrieve.
+//   - opts: Optional settings, such as WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []Challenge: The challenges, in the same order as ids.
+//   - error: Any error encountered while retrieving the challenges.
+func (c *Client) BatchGetChallenges(ids []string, opts ...Option) ([]Challenge, error) {
+	return c.BatchGetChallengesCtx(c.context(), ids, opts...)
+}
+
+// BatchGetChallengesCtx is like BatchGetChallenges but uses the given context instead of the client's Context.
+func (c *Client) BatchGetChallengesCtx(ctx context.Context, ids []string, opts ...Option) ([]Challenge, error) {
+	return batchQuery[Challenge](ctx, c, "BatchGetChallenges", "challenge(challengeID: %s)", ids, graphqlString, nil, batchOptions(opts))
+}
+
+// BatchGetUserChallenges retrieves several challenges associated with a
+// specific user, aliasing up to WithBatchSize lookups into each GraphQL query.
+// Assignments are not included.
+//
+// Parameters:
+//   - userId: The unique identifier of the user.
+//   - ids: The unique identifiers of the challenges to retrieve.
+//   - opts: Optional settings, such as WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []Challenge: The challenges, in the same order as ids.
+//   - error: Any error encountered while retrieving the challenges.
+func (c *Client) BatchGetUserChallenges(userId string, ids []string, opts ...Option) ([]Challenge, error) {
+	return c.BatchGetUserChallengesCtx(c.context(), userId, ids, opts...)
+}
+
+// BatchGetUserChallengesCtx is like BatchGetUserChallenges but uses the given context instead of the client's Context.
+func (c *Client) BatchGetUserChallengesCtx(ctx context.Context, userId string, ids []string, opts ...Option) ([]Challenge, error) {
+	challenges, _, err := c.batchGetUserChallenges(ctx, userId, ids, batchOptions(opts))
+	return challenges, err
+}
+
+// batchGetUserChallenges is like BatchGetUserChallengesCtx but also returns
+// the error of each lookup, in the same order as ids.
+func (c *Client) batchGetUserChallenges(ctx context.Context, userId string, ids []string, options *options) ([]Challenge, []error, error) {
+	shared := map[string]any{
+		"userId": graphql.String(userId),
+	}
+	return batchQueryEach[Challenge](ctx, c, "BatchGetUserChallenges", "challenge(userID: $userId, challengeID: %s)", ids, graphqlString, shared, options)
+}
+
+// BatchGetUserInfo retrieves the information of several users using their
+// unique IDs, aliasing up to WithBatchSize lookups into each GraphQL query.
+//
+// Parameters:
+//   - userIds: The unique identifiers of the users.
+//   - opts: Optional settings, such as WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []UserInfo: The users' information, in the same order as userIds.
+//   - error: Any error encountered while retrieving the user information.
+func (c *Client) BatchGetUserInfo(userIds []string, opts ...Option) ([]UserInfo, error) {
+	return c.BatchGetUserInfoCtx(c.context(), userIds, opts...)
+}
+
+// BatchGetUserInfoCtx is like BatchGetUserInfo but uses the given context instead of the client's Context.
+func (c *Client) BatchGetUserInfoCtx(ctx context.Context, userIds []string, opts ...Option) ([]UserInfo, error) {
+	shared := map[string]any{
+		"teamSlug": graphql.String(c.TeamSlug),
+	}
+	users, err := batchQuery[User](ctx, c, "BatchGetUserInfo", "user(userID: %s)", userIds, graphqlString, shared, batchOptions(opts))
+	if users == nil {
+		return nil, err
+	}
+
+	infos := make([]UserInfo, len(users))
+	for i, u := range users {
+		infos[i], _ = userInfo(u)
+	}
+	return infos, err
+}
+
+// BatchGetSandboxes retrieves several sandboxes using their unique IDs,
+// aliasing up to WithBatchSize lookups into each GraphQL query.
+//
+// Parameters:
+//   - ids: The unique identifiers of the sandboxes to retrieve.
+//   - opts: Optional settings, such as WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []Sandbox: The sandboxes, in the same order as ids.
+//   - error: Any error encountered while retrieving the sandboxes.
+func (c *Client) BatchGetSandboxes(ids []string, opts ...Option) ([]Sandbox, error) {
+	return c.BatchGetSandboxesCtx(c.context(), ids, opts...)
+}
+
+// BatchGetSandboxesCtx is like BatchGetSandboxes but uses the given context instead of the client's Context.
+func (c *Client) BatchGetSandboxesCtx(ctx context.Context, ids []string, opts ...Option) ([]Sandbox, error) {
+	shared := map[string]any{
+		"teamSlug": graphql.String(c.TeamSlug),
+	}
+	return batchQuery[Sandbox](ctx, c, "BatchGetSandboxes", "sandbox(ID: %s)", ids, graphqlID, shared, batchOptions(opts))
+}
+
+// SandboxVariableRef identifies a variable of a host of a sandbox.
+type SandboxVariableRef struct {
+	Hostname string // The host the variable is set on.
+	Key      string // The key of the variable.
+}
+
+// BatchGetSandboxVariables retrieves several variables of a sandbox,
+// aliasing up to WithBatchSize lookups into each GraphQL query.
+//
+// Parameters:
+//   - playID: The unique identifier of the sandbox environment.
+//   - refs: The variables to retrieve.
+//   - opts: Optional settings, such as WithBatchSize, WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []string: The values of the variables, in the same order as refs.
+//     Variables that are not set have an empty value.
+//   - error: Any error encountered while retrieving the variables.
+func (c *Client) BatchGetSandboxVariables(playID string, refs []SandboxVariableRef, opts ...Option) ([]string, error) {
+	return c.BatchGetSandboxVariablesCtx(c.context(), playID, refs, opts...)
+}
+
+// BatchGetSandboxVariablesCtx is like BatchGetSandboxVariables but uses the given context instead of the client's Context.
+func (c *Client) BatchGetSandboxVariablesCtx(ctx context.Context, playID string, refs []SandboxVariableRef, opts ...Option) ([]string, error) {
+	shared := map[string]any{
+		"sandboxID": graphql.String(playID),
+	}
+	vars, _, err := batchLookup[SandboxVar](ctx, c, "BatchGetSandboxVariables", len(refs), func(i int, n int) (string, map[string]any) {
+		field := fmt.Sprintf("getSandboxVariable(sandboxID: $sandboxID, hostname: $hostname%d, key: $key%d)", n, n)
+		return field, map[string]any{
+			fmt.Sprintf("hostname%d", n): graphql.String(refs[i].Hostname),
+			fmt.Sprintf("key%d", n):      graphql.String(refs[i].Key),
+		}
+	}, shared, batchOptions(opts))
+	if vars == nil {
+		return nil, err
+	}
+
+	values := make([]string, len(vars))
+	for i, v := range vars {
+		values[i] = v.Value
+	}
+	return values, err
+}
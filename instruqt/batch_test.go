@@ -0,0 +1,202 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// graphQLRequest is the body of a GraphQL request.
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+func TestBatchGetUserChallenges(t *testing.T) {
+	var mu sync.Mutex
+	var requests []graphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		data := map[string]any{}
+		for key, value := range req.Variables {
+			if alias, ok := strings.CutPrefix(key, "id"); ok {
+				data["c"+alias] = map[string]any{"id": value, "status": "unlocked"}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions("test-token", "isovalent", WithBaseURL(server.URL))
+	ids := []string{"ch-1", "ch-2", "ch-3", "ch-4", "ch-5"}
+
+	challenges, err := client.BatchGetUserChallenges("user-123", ids, WithBatchSize(2))
+
+	assert.NoError(t, err)
+	assert.Len(t, challenges, 5)
+	for i, ch := range challenges {
+		assert.Equal(t, ids[i], ch.Id)
+		assert.Equal(t, "unlocked", ch.Status)
+	}
+
+	assert.Len(t, requests, 3, "Expected one query per batch")
+	for _, req := range requests {
+		assert.Contains(t, req.Query, "c0: challenge(userID: $userId, challengeID: $id0)")
+		assert.Equal(t, "user-123", req.Variables["userId"])
+	}
+}
+
+func TestBatchGetUserInfo(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		TeamSlug:      "isovalent",
+	}
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["teamSlug"] == graphql.String("isovalent") && vars["id0"] == graphql.String("user-1") && vars["id1"] == graphql.String("user-2")
+	})).Run(func(args mock.Arguments) {
+		q := args.Get(1)
+		data := `{"c0":{"id":"user-1","details":{"firstName":"Ada","lastName":"Lovelace","email":"ada@example.com"}},"c1":{"id":"user-2","profile":{"display_name":"Grace Hopper","email":"grace@example.com"}}}`
+		assert.NoError(t, json.Unmarshal([]byte(data), q))
+	}).Return(nil).Once()
+
+	infos, err := client.BatchGetUserInfo([]string{"user-1", "user-2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []UserInfo{
+		{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"},
+		{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com"},
+	}, infos)
+	mockClient.AssertExpectations(t)
+}
+
+func TestBatchGetSandboxes_CollectAll(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["id0"] == graphql.ID("sandbox-1")
+	})).Return(errors.New("graphql error")).Once()
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal([]byte(`{"c0":{"id":"sandbox-2"}}`), args.Get(1)))
+	}).Return(nil).Once()
+
+	sandboxes, err := client.BatchGetSandboxes([]string{"sandbox-1", "sandbox-2"}, WithBatchSize(1), WithConcurrency(1), WithErrorPolicy(ErrorPolicyCollectAll))
+
+	assert.ErrorContains(t, err, "graphql error")
+	assert.Len(t, sandboxes, 2)
+	assert.Empty(t, sandboxes[0].Id)
+	assert.Equal(t, "sandbox-2", sandboxes[1].Id)
+}
+
+func TestGetUserTrackById_WithBatchSize(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &sandboxTrackQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		for i := 0; i < 4; i++ {
+			q.Challenges = append(q.Challenges, Challenge{Id: fmt.Sprintf("challenge-%d", i)})
+		}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		vars := args.Get(2).(map[string]interface{})
+		data := fmt.Sprintf(`{"c0":{"id":%q,"status":"completed"},"c1":{"id":%q,"status":"unlocked"},"c2":{"id":%q,"status":"locked"},"c3":{"id":%q,"status":"locked"}}`,
+			vars["id0"], vars["id1"], vars["id2"], vars["id3"])
+		assert.NoError(t, json.Unmarshal([]byte(data), args.Get(1)))
+	}).Return(nil).Once()
+
+	track, err := client.GetUserTrackById("user-123", "track-123", WithChallenges(), WithBatchSize(10))
+
+	assert.NoError(t, err)
+	assert.Len(t, track.Challenges, 4)
+	assert.Equal(t, "challenge-1", track.Challenges[1].Id)
+	assert.Equal(t, "unlocked", track.Challenges[1].Status)
+	mockClient.AssertNumberOfCalls(t, "Query", 3)
+}
+
+func TestGetUserTrackById_WithBatchSizeCollectAll(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &sandboxTrackQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "challenge-0", Title: "Install"}, {Id: "challenge-1", Title: "Observe"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["id0"] == graphql.String("challenge-0")
+	})).Return(errors.New("graphql error")).Once()
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal([]byte(`{"c0":{"id":"challenge-1","status":"unlocked"}}`), args.Get(1)))
+	}).Return(nil).Once()
+
+	track, err := client.GetUserTrackById("user-123", "track-123", WithChallenges(), WithBatchSize(1), WithConcurrency(1), WithErrorPolicy(ErrorPolicyCollectAll))
+
+	assert.ErrorContains(t, err, "graphql error")
+	assert.Equal(t, []Challenge{{Id: "challenge-0", Title: "Install"}, {Id: "challenge-1", Status: "unlocked"}}, track.Challenges,
+		"Expected the challenges whose lookup failed to be kept")
+}
+
+func TestBatchGetSandboxVariables(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, mock.Anything, map[string]interface{}{
+		"sandboxID": graphql.String("sandbox-1"),
+		"hostname0": graphql.String("server"),
+		"key0":      graphql.String("TOKEN"),
+		"hostname1": graphql.String("client"),
+		"key1":      graphql.String("URL"),
+	}).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal([]byte(`{"c0":{"key":"TOKEN","value":"secret"},"c1":{"key":"URL","value":""}}`), args.Get(1)))
+	}).Return(nil).Once()
+
+	values, err := client.BatchGetSandboxVariables("sandbox-1", []SandboxVariableRef{
+		{Hostname: "server", Key: "TOKEN"},
+		{Hostname: "client", Key: "URL"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"secret", ""}, values)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,364 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache stores the encoded results of read queries. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, if it has not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for the given duration.
+	Set(key string, value []byte, ttl time.Duration)
+	// Invalidate removes every value whose key starts with prefix.
+	Invalidate(prefix string)
+}
+
+// CacheEntity identifies a kind of entity whose queries can be cached.
+type CacheEntity string
+
+// Entities whose read queries are cached when a Cache is configured.
+const (
+	CacheTracks     CacheEntity = "tracks"     // GetTrackById, GetTrackBySlug and GetTracks.
+	CacheChallenges CacheEntity = "challenges" // GetChallenge, GetChallenges and GetChallengeWithAssignment.
+	CacheInvites    CacheEntity = "invites"    // GetInvite, GetInvites, GetInviteTracks and GetInvitesTracks.
+	CacheTeam       CacheEntity = "team"       // GetTPGPublicKey.
+)
+
+// cachedOperations maps the cacheable client operations to their entity.
+// Queries scoped to a user, such as GetUserChallenge, are never cached.
+var cachedOperations = map[string]CacheEntity{
+	"GetTrackById":               CacheTracks,
+	"GetTrackBySlug":             CacheTracks,
+	"GetTracks":                  CacheTracks,
+	"GetChallenge":               CacheChallenges,
+	"GetChallenges":              CacheChallenges,
+	"GetChallengeWithAssignment": CacheChallenges,
+	"GetInvite":                  CacheInvites,
+	"GetInvites":                 CacheInvites,
+	"GetInviteTracks":            CacheInvites,
+	"GetInvitesTracks":           CacheInvites,
+	"GetTPGPublicKey":            CacheTeam,
+}
+
+// defaultCacheTTLs are the durations for which query results are cached,
+// unless overridden with WithCacheTTL.
+var defaultCacheTTLs = map[CacheEntity]time.Duration{
+	CacheTracks:     5 * time.Minute,
+	CacheChallenges: 5 * time.Minute,
+	CacheInvites:    time.Minute,
+	CacheTeam:       time.Hour,
+}
+
+// WithCache makes the client consult cache before running read queries for
+// tracks, challenges, invites and the team's public key. Concurrent
+// identical queries are de-duplicated into a single request. A cache can be
+// shared by several clients: their results are keyed by team slug and token.
+// Usage: NewClientWithOptions(token, teamSlug, WithCache(NewLRUCache(1000)))
+func WithCache(cache Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long the query results of an entity are cached.
+// A zero duration disables caching for the entity.
+// Usage: NewClientWithOptions(token, teamSlug, WithCache(cache), WithCacheTTL(CacheTracks, time.Hour))
+func WithCacheTTL(entity CacheEntity, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if o.cacheTTLs == nil {
+			o.cacheTTLs = make(map[CacheEntity]time.Duration)
+		}
+		o.cacheTTLs[entity] = ttl
+	}
+}
+
+// defaultSharedQueryTimeout bounds the queries shared by concurrent callers
+// of a client without a timeout, unless a caller allows more time.
+const defaultSharedQueryTimeout = time.Minute
+
+// responseCache caches the results of read queries made by a client.
+type responseCache struct {
+	store     Cache
+	ttls      map[CacheEntity]time.Duration
+	tokenHash string        // Identifies the token of the client in keys, without exposing it.
+	timeout   time.Duration // The minimum time allowed to a shared query.
+	group     singleflight.Group
+}
+
+// newResponseCache creates a responseCache backed by store for a client
+// using token, with the default TTLs overridden by ttls. Shared queries are
+// allowed at least timeout, or defaultSharedQueryTimeout if it is zero.
+func newResponseCache(store Cache, ttls map[CacheEntity]time.Duration, token string, timeout time.Duration) *responseCache {
+	sum := sha256.Sum256([]byte(token))
+	rc := &responseCache{
+		store:     store,
+		ttls:      make(map[CacheEntity]time.Duration, len(defaultCacheTTLs)),
+		tokenHash: hex.EncodeToString(sum[:8]),
+		timeout:   timeout,
+	}
+	if rc.timeout <= 0 {
+		rc.timeout = defaultSharedQueryTimeout
+	}
+	for entity, ttl := range defaultCacheTTLs {
+		rc.ttls[entity] = ttl
+	}
+	for entity, ttl := range ttls {
+		rc.ttls[entity] = ttl
+	}
+	return rc
+}
+
+// cachePrefix returns the prefix of the keys of the cached query results of
+// an entity. It includes the team slug and token of the client, so that
+// clients sharing a store are never served each other's results.
+func (c *Client) cachePrefix(entity CacheEntity) string {
+	return c.TeamSlug + ":" + c.cache.tokenHash + ":" + string(entity) + ":"
+}
+
+// cacheKey returns the key under which the result of a query is cached and
+// its TTL, or false if the operation is not cached.
+func (c *Client) cacheKey(operation string, variables map[string]any) (string, time.Duration, bool) {
+	entity, ok := cachedOperations[operation]
+	if !ok || c.cache.ttls[entity] <= 0 {
+		return "", 0, false
+	}
+
+	vars, err := json.Marshal(variables)
+	if err != nil {
+		return "", 0, false
+	}
+	return c.cachePrefix(entity) + operation + ":" + string(vars), c.cache.ttls[entity], true
+}
+
+// cachedQuery executes a read query through the client's cache, if the
+// operation is cacheable, and reports whether it did.
+func (c *Client) cachedQuery(ctx context.Context, operation string, q any, variables map[string]any) (bool, error) {
+	if c.cache == nil {
+		return false, nil
+	}
+	key, ttl, ok := c.cacheKey(operation, variables)
+	if !ok {
+		return false, nil
+	}
+
+	if b, ok := c.cache.store.Get(key); ok {
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(q); err == nil {
+			c.logger().LogAttrs(ctx, slog.LevelDebug, "instruqt cache hit",
+				slog.String("operation", operation),
+				slog.String("team_slug", c.TeamSlug),
+			)
+			return true, nil
+		}
+	}
+
+	// Concurrent identical queries share a single request, decoded into a
+	// fresh value and handed to every caller as gob. The request outlives
+	// the caller that started it, so that canceling one caller does not fail
+	// the others, but is bounded by the client's timeout or the caller's
+	// deadline, whichever is later; each caller only waits for it as long as
+	// its own context allows.
+	ch := c.cache.group.DoChan(key, func() (any, error) {
+		deadline := time.Now().Add(c.cache.timeout)
+		if d, ok := ctx.Deadline(); ok && d.After(deadline) {
+			deadline = d
+		}
+		sharedCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
+		defer cancel()
+
+		shared := reflect.New(reflect.TypeOf(q).Elem()).Interface()
+		if err := c.doQuery(sharedCtx, operation, shared, variables); err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(shared); err != nil {
+			c.logger().LogAttrs(ctx, slog.LevelDebug, "instruqt cache encoding failed",
+				slog.String("operation", operation),
+				slog.String("error", err.Error()),
+			)
+			return nil, nil
+		}
+		c.cache.store.Set(key, buf.Bytes(), ttl)
+		return buf.Bytes(), nil
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return true, wrapError(operation, ctx.Err())
+	}
+	if res.Err != nil {
+		return true, res.Err
+	}
+	if res.Val == nil {
+		// The shared result could not be encoded; query on our own.
+		return true, c.doQuery(ctx, operation, q, variables)
+	}
+	return true, wrapError(operation, gob.NewDecoder(bytes.NewReader(res.Val.([]byte))).Decode(q))
+}
+
+// InvalidateCache removes the cached query results of the given entities,
+// or of all entities if none are given.
+func (c *Client) InvalidateCache(entities ...CacheEntity) {
+	if c.cache == nil {
+		return
+	}
+	if len(entities) == 0 {
+		for entity := range defaultCacheTTLs {
+			entities = append(entities, entity)
+		}
+	}
+	for _, entity := range entities {
+		c.cache.store.Invalidate(c.cachePrefix(entity))
+	}
+}
+
+// InvalidateCacheForEvent removes the cached query results made stale by a
+// webhook event: invite events, and track events of plays started from an
+// invite, invalidate invites; review events invalidate tracks, whose review
+// statistics change.
+func (c *Client) InvalidateCacheForEvent(event WebhookEvent) {
+	kind, _, _ := strings.Cut(event.Type, ".")
+	switch {
+	case kind == "invite", kind == "track" && event.InviteId != "":
+		c.InvalidateCache(CacheInvites)
+	case kind == "review":
+		c.InvalidateCache(CacheTracks)
+	}
+}
+
+// InvalidatingWebhookHandler wraps a WebhookHandler so that the cache is
+// updated with InvalidateCacheForEvent before each event is handled.
+//
+// Usage: http.Handle("/webhook", HandleWebhook(client.InvalidatingWebhookHandler(handler), secret))
+func (c *Client) InvalidatingWebhookHandler(handler WebhookHandler) WebhookHandler {
+	return func(w http.ResponseWriter, r *http.Request, webhook WebhookEvent) error {
+		c.InvalidateCacheForEvent(webhook)
+		return handler(w, r, webhook)
+	}
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry
+// once it holds its maximum number of entries. Expired entries are dropped
+// when accessed.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Most recently used entries first.
+	now        func() time.Time
+}
+
+// lruEntry is a value stored in an LRUCache.
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache creates an in-memory cache holding up to maxEntries entries.
+// A maxEntries of zero or less means no limit.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get returns the value stored under key, if it has not expired.
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if !l.now().Before(entry.expires) {
+		l.remove(el)
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores value under key for the given duration, evicting the least
+// recently used entry if the cache is full.
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := l.now().Add(ttl)
+	if el, ok := l.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+	}
+}
+
+// Invalidate removes every value whose key starts with prefix.
+func (l *LRUCache) Invalidate(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, el := range l.entries {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(el)
+		}
+	}
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// not yet dropped.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// remove drops an entry from the cache. The caller must hold l.mu.
+func (l *LRUCache) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.entries, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,261 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newCachedClient returns a client backed by mockClient that caches its
+// queries in cache.
+func newCachedClient(mockClient *MockGraphQLClient, cache Cache, opts ...ClientOption) *Client {
+	client := NewClientWithOptions("test-token", "isovalent", append([]ClientOption{WithCache(cache)}, opts...)...)
+	client.GraphQLClient = mockClient
+	return client
+}
+
+func TestCache_ServesRepeatedQueries(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &trackQueryBySlug{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*trackQueryBySlug)
+		q.Track = Track{Id: "track-123", Slug: "test-slug", TrackTags: []TrackTag{{Value: "cilium"}}}
+	}).Return(nil).Once()
+
+	first, err := client.GetTrackBySlug("test-slug")
+	assert.NoError(t, err)
+	first.TrackTags[0].Value = "modified"
+
+	second, err := client.GetTrackBySlug("test-slug")
+	assert.NoError(t, err)
+	assert.Equal(t, "track-123", second.Id)
+	assert.Equal(t, "cilium", second.TrackTags[0].Value, "Expected cached results not to share memory with callers")
+
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestCache_KeysOnVariables(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*trackQuery)
+		q.Track.Id = string(args.Get(2).(map[string]interface{})["trackId"].(graphql.String))
+	}).Return(nil)
+
+	for _, id := range []string{"track-1", "track-2", "track-1"} {
+		track, err := client.GetTrackById(id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, track.Id)
+	}
+
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestCache_SkipsUserScopedQueries(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &userChallengeQuery{}, mock.Anything).Return(nil)
+
+	_, _ = client.GetUserChallenge("user-123", "challenge-123")
+	_, _ = client.GetUserChallenge("user-123", "challenge-123")
+
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestCache_EntityTTL(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	cache := NewLRUCache(10)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	client := newCachedClient(mockClient, cache, WithCacheTTL(CacheTeam, time.Minute))
+
+	mockClient.On("Query", mock.Anything, &teamQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*teamQuery)
+		q.Team.TPGPublicKey = "public-key"
+	}).Return(nil)
+
+	_, _ = client.GetTPGPublicKey()
+	now = now.Add(30 * time.Second)
+	_, _ = client.GetTPGPublicKey()
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+
+	now = now.Add(time.Minute)
+	key, err := client.GetTPGPublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, "public-key", key)
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestCache_InvalidateCacheForEvent(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &inviteQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Return(nil)
+
+	_, _ = client.GetInvite("invite-123")
+	_, _ = client.GetTrackById("track-123")
+
+	client.InvalidateCacheForEvent(WebhookEvent{Type: "invite.claimed", InviteId: "invite-123"})
+
+	_, _ = client.GetInvite("invite-123")
+	_, _ = client.GetTrackById("track-123")
+
+	mockClient.AssertNumberOfCalls(t, "Query", 3)
+}
+
+func TestCache_DeduplicatesConcurrentQueries(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "challenge-1"}}
+	}).Return(nil).Once()
+
+	results := make([][]Challenge, 5)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = client.GetChallenges("track-123")
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = client.GetChallenges("track-123")
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, challenges := range results {
+		assert.Equal(t, []Challenge{{Id: "challenge-1"}}, challenges)
+	}
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestCache_CanceledLeaderDoesNotFailFollowers(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+		assert.NoError(t, args.Get(0).(context.Context).Err(), "Expected the shared query to outlive its leader")
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "challenge-1"}}
+	}).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetChallengesCtx(ctx, "track-123")
+		leaderErr <- err
+	}()
+	<-started
+
+	var (
+		wg       sync.WaitGroup
+		follower []Challenge
+		err      error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		follower, err = client.GetChallenges("track-123")
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	close(release)
+	wg.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, []Challenge{{Id: "challenge-1"}}, follower)
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestCache_SharedQueryDeadline(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10), WithTimeout(20*time.Millisecond))
+
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.DeadlineExceeded).Once()
+
+	_, err := client.GetChallenges("track-123")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Expected a stuck shared query to time out")
+	mockClient.AssertExpectations(t)
+}
+
+func TestCache_KeysOnClient(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	cache := NewLRUCache(10)
+	client := newCachedClient(mockClient, cache)
+	other := NewClientWithOptions("other-token", "isovalent", WithCache(cache))
+	other.GraphQLClient = mockClient
+
+	mockClient.On("Query", mock.Anything, &challengeQuery{}, mock.Anything).Return(nil)
+
+	_, _ = client.GetChallenge("challenge-123")
+	_, _ = other.GetChallenge("challenge-123")
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+
+	_, _ = client.GetChallenge("challenge-123")
+	other.InvalidateCache(CacheChallenges)
+	_, _ = client.GetChallenge("challenge-123")
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestLRUCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache(2)
+
+	cache.Set("a", []byte("1"), time.Minute)
+	cache.Set("b", []byte("2"), time.Minute)
+	_, _ = cache.Get("a")
+	cache.Set("c", []byte("3"), time.Minute)
+
+	_, ok := cache.Get("b")
+	assert.False(t, ok, "Expected the least recently used entry to be evicted")
+	v, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Invalidate("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,300 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Catalog is a snapshot of the public content of a team: its tracks with
+// their challenges, its invites with their tracks, and the tracks in
+// maintenance. Catalogs can be serialized to JSON and compared with Diff.
+type Catalog struct {
+	TeamSlug            string        `json:"team_slug"`             // The slug of the team.
+	TakenAt             time.Time     `json:"taken_at"`              // The time the snapshot was taken.
+	Tracks              []Track       `json:"tracks"`                // The tracks of the team, with their challenges.
+	Invites             []TrackInvite `json:"invites"`               // The invites of the team, with their tracks.
+	TracksInMaintenance []string      `json:"tracks_in_maintenance"` // The slugs of the tracks in maintenance.
+}
+
+// GetCatalog takes a snapshot of the team's catalog.
+//
+// Parameters:
+//   - opts: Optional settings applied when fetching challenges, such as
+//     WithConcurrency, WithBatchSize or WithErrorPolicy.
+//
+// Returns:
+//   - *Catalog: The snapshot of the catalog. With ErrorPolicyCollectAll, it
+//     is returned along with the error, leaving out the challenges that
+//     could not be retrieved.
+//   - error: Any error encountered while taking the snapshot.
+func (c *Client) GetCatalog(opts ...Option) (*Catalog, error) {
+	return c.GetCatalogCtx(c.context(), opts...)
+}
+
+// GetCatalogCtx is like GetCatalog but uses the given context instead of the client's Context.
+func (c *Client) GetCatalogCtx(ctx context.Context, opts ...Option) (catalog *Catalog, err error) {
+	ctx, end := c.startOperation(ctx, "GetCatalog")
+	defer func() { end(err) }()
+
+	catalog = &Catalog{
+		TeamSlug: c.TeamSlug,
+		TakenAt:  time.Now().UTC(),
+	}
+
+	tracks, tracksErr := c.GetTracksCtx(ctx, append([]Option{WithChallenges()}, opts...)...)
+	if tracks == nil && tracksErr != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", tracksErr)
+	}
+	catalog.Tracks = tracks
+
+	catalog.Invites, err = c.GetInvitesCtx(ctx, WithTracks())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invites: %w", err)
+	}
+
+	catalog.TracksInMaintenance, err = c.GetTracksInMaintenanceCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks in maintenance: %w", err)
+	}
+
+	if tracksErr != nil {
+		// With ErrorPolicyCollectAll, the tracks are returned even though
+		// the challenges of some of them could not be retrieved.
+		return catalog, fmt.Errorf("failed to get tracks: %w", tracksErr)
+	}
+	return catalog, nil
+}
+
+// ChangeKind defines the kind of change made to an entity between two
+// catalogs.
+type ChangeKind string
+
+// Constants representing the kinds of changes.
+const (
+	ChangeAdded   ChangeKind = "added"   // The entity only exists in the new catalog.
+	ChangeRemoved ChangeKind = "removed" // The entity only exists in the old catalog.
+	ChangeChanged ChangeKind = "changed" // Some fields of the entity differ.
+)
+
+// FieldChange is a field of an entity whose value differs between two catalogs.
+type FieldChange struct {
+	Field string `json:"field"` // The name of the field.
+	Old   any    `json:"old"`   // The value in the old catalog.
+	New   any    `json:"new"`   // The value in the new catalog.
+}
+
+// EntityChange is a change made to a track, challenge or invite.
+type EntityChange struct {
+	Kind    ChangeKind    `json:"kind"`               // The kind of change.
+	Id      string        `json:"id"`                 // The unique identifier of the entity.
+	Name    string        `json:"name"`               // The slug of the track or challenge, or the title of the invite.
+	TrackId string        `json:"track_id,omitempty"` // The track of a challenge.
+	Fields  []FieldChange `json:"fields,omitempty"`   // The changed fields, for ChangeChanged.
+}
+
+// CatalogDiff holds the differences between two catalogs.
+type CatalogDiff struct {
+	Tracks     []EntityChange `json:"tracks,omitempty"`
+	Challenges []EntityChange `json:"challenges,omitempty"`
+	Invites    []EntityChange `json:"invites,omitempty"`
+}
+
+// Empty reports whether the catalogs were identical.
+func (d CatalogDiff) Empty() bool {
+	return len(d.Tracks) == 0 && len(d.Challenges) == 0 && len(d.Invites) == 0
+}
+
+// String returns a human-readable summary of the differences, one entity
+// per line.
+func (d CatalogDiff) String() string {
+	var b strings.Builder
+	for _, group := range []struct {
+		entity  string
+		changes []EntityChange
+	}{{"track", d.Tracks}, {"challenge", d.Challenges}, {"invite", d.Invites}} {
+		for _, change := range group.changes {
+			fmt.Fprintf(&b, "%s %s %s (%s)", change.Kind, group.entity, change.Name, change.Id)
+			for i, field := range change.Fields {
+				sep := ", "
+				if i == 0 {
+					sep = ": "
+				}
+				fmt.Fprintf(&b, "%s%s %v -> %v", sep, field.Field, field.Old, field.New)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Fields that are not compared by Diff, because they are compared
+// separately or change without the content being edited.
+var (
+	trackDiffSkip     = []string{"Challenges", "Statistics", "TrackReviews", "Last_Update"}
+	challengeDiffSkip = []string{"Status", "Attempts", "Track"}
+	inviteDiffSkip    = []string{"Tracks", "Claims", "ClaimCount", "InviteCount", "CurrentUserAllowed", "CurrentUserClaimed", "DaysUntil", "CanClaim", "Last_Updated"}
+)
+
+// Diff reports the tracks, challenges and invites added, removed and changed
+// between two catalogs, matching entities by ID. Changed entities list
+// their differing fields. Counters and user-scoped fields, such as review
+// statistics, claim counts and challenge statuses, are ignored.
+//
+// Parameters:
+//   - old: The earlier catalog.
+//   - new: The later catalog.
+//
+// Returns:
+//   - CatalogDiff: The differences between the catalogs.
+func Diff(old *Catalog, new *Catalog) CatalogDiff {
+	var d CatalogDiff
+
+	d.Tracks = diffEntities(old.Tracks, new.Tracks,
+		func(t Track) (string, string) { return t.Id, t.Slug },
+		func(o, n Track) []FieldChange {
+			fields := diffFields(o, n, trackDiffSkip)
+			if om, nm := slices.Contains(old.TracksInMaintenance, o.Slug), slices.Contains(new.TracksInMaintenance, n.Slug); om != nm {
+				fields = append(fields, FieldChange{Field: "Maintenance", Old: om, New: nm})
+			}
+			return fields
+		})
+
+	d.Challenges = diffEntities(catalogChallenges(old), catalogChallenges(new),
+		func(ch Challenge) (string, string) { return ch.Id, ch.Slug },
+		func(o, n Challenge) []FieldChange { return diffFields(o, n, challengeDiffSkip) })
+
+	d.Invites = diffEntities(old.Invites, new.Invites,
+		func(i TrackInvite) (string, string) { return i.Id, i.Title },
+		func(o, n TrackInvite) []FieldChange {
+			fields := diffFields(o, n, inviteDiffSkip)
+			if ot, nt := inviteTrackIds(o), inviteTrackIds(n); !slices.Equal(ot, nt) {
+				fields = append(fields, FieldChange{Field: "Tracks", Old: ot, New: nt})
+			}
+			return fields
+		})
+
+	return d
+}
+
+// catalogChallenges returns the challenges of all tracks of a catalog, with
+// their Track.Id set.
+func catalogChallenges(catalog *Catalog) []Challenge {
+	var challenges []Challenge
+	for _, t := range catalog.Tracks {
+		for _, ch := range t.Challenges {
+			ch.Track.Id = t.Id
+			challenges = append(challenges, ch)
+		}
+	}
+	return challenges
+}
+
+// inviteTrackIds returns the IDs of the tracks of an invite.
+func inviteTrackIds(invite TrackInvite) []string {
+	ids := make([]string, len(invite.Tracks))
+	for i, t := range invite.Tracks {
+		ids[i] = t.Id
+	}
+	return ids
+}
+
+// diffEntities compares two lists of entities by ID. Changes are listed in
+// the order of the new list, followed by removed entities.
+func diffEntities[T any](old []T, new []T, key func(T) (id string, name string), fields func(old, new T) []FieldChange) []EntityChange {
+	oldByID := make(map[string]T, len(old))
+	for _, e := range old {
+		id, _ := key(e)
+		oldByID[id] = e
+	}
+
+	var changes []EntityChange
+	seen := make(map[string]bool, len(new))
+	for _, n := range new {
+		id, name := key(n)
+		seen[id] = true
+		change := EntityChange{Id: id, Name: name, TrackId: entityTrackId(n)}
+
+		o, ok := oldByID[id]
+		if !ok {
+			change.Kind = ChangeAdded
+			changes = append(changes, change)
+			continue
+		}
+		if change.Fields = fields(o, n); len(change.Fields) > 0 {
+			change.Kind = ChangeChanged
+			changes = append(changes, change)
+		}
+	}
+
+	for _, o := range old {
+		id, name := key(o)
+		if !seen[id] {
+			changes = append(changes, EntityChange{Kind: ChangeRemoved, Id: id, Name: name, TrackId: entityTrackId(o)})
+		}
+	}
+	return changes
+}
+
+// entityTrackId returns the track of a challenge, or an empty string for
+// other entities.
+func entityTrackId(e any) string {
+	if ch, ok := e.(Challenge); ok {
+		return ch.Track.Id
+	}
+	return ""
+}
+
+// diffFields compares the exported fields of two structs of the same type,
+// except those in skip. Times are compared with time.Time.Equal, so that
+// catalogs read back from JSON compare equal.
+func diffFields(old any, new any, skip []string) []FieldChange {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	t := ov.Type()
+
+	var fields []FieldChange
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || slices.Contains(skip, f.Name) {
+			continue
+		}
+
+		o, n := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if ot, ok := o.(time.Time); ok {
+			if ot.Equal(n.(time.Time)) {
+				continue
+			}
+		} else if reflect.DeepEqual(o, n) || (isEmpty(ov.Field(i)) && isEmpty(nv.Field(i))) {
+			continue
+		}
+		fields = append(fields, FieldChange{Field: f.Name, Old: o, New: n})
+	}
+	return fields
+}
+
+// isEmpty reports whether v is a nil or empty slice or map, so that these
+// compare equal after a JSON round trip.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testCatalog() *Catalog {
+	return &Catalog{
+		TeamSlug: "isovalent",
+		TakenAt:  time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Tracks: []Track{{
+			Id:        "track-1",
+			Slug:      "getting-started",
+			Title:     "Getting Started",
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Challenges: []Challenge{
+				{Id: "ch-1", Slug: "intro", Title: "Introduction", Index: 0},
+				{Id: "ch-2", Slug: "install", Title: "Install", Index: 1},
+			},
+		}},
+		Invites: []TrackInvite{{
+			Id:     "invite-1",
+			Title:  "Workshop",
+			Tracks: []Track{{Id: "track-1"}},
+		}},
+	}
+}
+
+func TestGetCatalog_CollectAll(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["trackId"] == graphql.String("track-2")
+	})).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "ch-1"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &invitesQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &invitesTracksQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &tracksInMaintenanceQuery{}, mock.Anything).Return(nil)
+
+	catalog, err := client.GetCatalog(WithErrorPolicy(ErrorPolicyCollectAll))
+
+	assert.ErrorContains(t, err, "graphql error")
+	require.NotNil(t, catalog)
+	require.Len(t, catalog.Tracks, 2)
+	assert.Equal(t, []Challenge{{Id: "ch-1"}}, catalog.Tracks[0].Challenges)
+	assert.Empty(t, catalog.Tracks[1].Challenges)
+}
+
+func TestDiff_Identical(t *testing.T) {
+	old := testCatalog()
+
+	// Round-trip through JSON, as catalogs are typically stored on disk.
+	data, err := json.Marshal(old)
+	require.NoError(t, err)
+	var new Catalog
+	require.NoError(t, json.Unmarshal(data, &new))
+	new.Tracks[0].CreatedAt = new.Tracks[0].CreatedAt.In(time.FixedZone("CEST", 2*60*60))
+	new.Invites[0].ClaimCount = 42
+
+	d := Diff(old, &new)
+
+	assert.True(t, d.Empty(), d.String())
+}
+
+func TestDiff(t *testing.T) {
+	old := testCatalog()
+	new := testCatalog()
+	new.Tracks[0].Title = "Getting Started with Cilium"
+	new.Tracks[0].Challenges = []Challenge{
+		{Id: "ch-2", Slug: "install", Title: "Install", Index: 0},
+		{Id: "ch-3", Slug: "observe", Title: "Observe", Index: 1},
+	}
+	new.Tracks = append(new.Tracks, Track{Id: "track-2", Slug: "advanced"})
+	new.TracksInMaintenance = []string{"getting-started"}
+	new.Invites[0].Tracks = append(new.Invites[0].Tracks, Track{Id: "track-2"})
+
+	d := Diff(old, new)
+
+	assert.Equal(t, []EntityChange{
+		{Kind: ChangeChanged, Id: "track-1", Name: "getting-started", Fields: []FieldChange{
+			{Field: "Title", Old: "Getting Started", New: "Getting Started with Cilium"},
+			{Field: "Maintenance", Old: false, New: true},
+		}},
+		{Kind: ChangeAdded, Id: "track-2", Name: "advanced"},
+	}, d.Tracks)
+	assert.Equal(t, []EntityChange{
+		{Kind: ChangeChanged, Id: "ch-2", Name: "install", TrackId: "track-1", Fields: []FieldChange{
+			{Field: "Index", Old: 1, New: 0},
+		}},
+		{Kind: ChangeAdded, Id: "ch-3", Name: "observe", TrackId: "track-1"},
+		{Kind: ChangeRemoved, Id: "ch-1", Name: "intro", TrackId: "track-1"},
+	}, d.Challenges)
+	assert.Equal(t, []EntityChange{
+		{Kind: ChangeChanged, Id: "invite-1", Name: "Workshop", Fields: []FieldChange{
+			{Field: "Tracks", Old: []string{"track-1"}, New: []string{"track-1", "track-2"}},
+		}},
+	}, d.Invites)
+	assert.Contains(t, d.String(), "changed track getting-started (track-1): Title Getting Started -> Getting Started with Cilium, Maintenance false -> true\n")
+}
@@ -15,6 +15,8 @@
 package instruqt
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -44,11 +46,15 @@ type Challenge struct {
 	Track  struct {
 		Id string // The identifier for the track associated with the challenge.
 	} `json:"-"`
-	Attempts []struct {
-		Message   string    `json:"message"`   // The message returned by the attempts.
-		Timestamp time.Time `json:"timestamp"` // The timestamp of the attempt.
-	} `json:"attempts"` // The attempts made on the challenge by the user.
-	Assignment string `graphql:"-" json:"assignment"` // The assignment details for the challenge.
+	Attempts   []ChallengeAttempt `json:"attempts"`               // The attempts made on the challenge by the user.
+	Assignment string             `graphql:"-" json:"assignment"` // The assignment details for the challenge.
+}
+
+// ChallengeAttempt is a check of a challenge by a user. The message of a
+// passed check is empty.
+type ChallengeAttempt struct {
+	Message   string    `json:"message"`   // The message returned by the attempts.
+	Timestamp time.Time `json:"timestamp"` // The timestamp of the attempt.
 }
 
 // GetChallenge retrieves a challenge from Instruqt using its unique challenge ID.
@@ -60,6 +66,11 @@ type Challenge struct {
 //   - Challenge: The challenge details if found.
 //   - error: Any error encountered while retrieving the challenge.
 func (c *Client) GetChallenge(id string, opts ...Option) (ch Challenge, err error) {
+	return c.GetChallengeCtx(c.context(), id, opts...)
+}
+
+// GetChallengeCtx is like GetChallenge but uses the given context instead of the client's Context.
+func (c *Client) GetChallengeCtx(ctx context.Context, id string, opts ...Option) (ch Challenge, err error) {
 	if id == "" {
 		return ch, nil
 	}
@@ -79,12 +90,12 @@ func (c *Client) GetChallenge(id string, opts ...Option) (ch Challenge, err erro
 		"challengeId": graphql.String(id),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetChallenge", &q, variables); err != nil {
 		return ch, err
 	}
 
 	if filters.includeAssignment {
-		cc, err := c.GetChallengeWithAssignment(id, filters.parseAssignmentVariables)
+		cc, err := c.GetChallengeWithAssignmentCtx(ctx, id, filters.parseAssignmentVariables)
 		if err != nil {
 			return ch, err
 		}
@@ -106,6 +117,11 @@ func (c *Client) GetChallenge(id string, opts ...Option) (ch Challenge, err erro
 //   - Challenge: The challenge details if found.
 //   - error: Any error encountered while retrieving the challenge.
 func (c *Client) GetUserChallenge(userId string, id string, opts ...Option) (ch Challenge, err error) {
+	return c.GetUserChallengeCtx(c.context(), userId, id, opts...)
+}
+
+// GetUserChallengeCtx is like GetUserChallenge but uses the given context instead of the client's Context.
+func (c *Client) GetUserChallengeCtx(ctx context.Context, userId string, id string, opts ...Option) (ch Challenge, err error) {
 	if id == "" {
 		return ch, nil
 	}
@@ -126,12 +142,12 @@ func (c *Client) GetUserChallenge(userId string, id string, opts ...Option) (ch
 		"userId":      graphql.String(userId),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetUserChallenge", &q, variables); err != nil {
 		return ch, err
 	}
 
 	if filters.includeAssignment {
-		cc, err := c.GetUserChallengeWithAssignment(userId, id, filters.parseAssignmentVariables)
+		cc, err := c.GetUserChallengeWithAssignmentCtx(ctx, userId, id, filters.parseAssignmentVariables)
 		if err != nil {
 			return ch, err
 		}
@@ -149,6 +165,11 @@ type ChallengeWithAssignment struct {
 
 // GetChallengeWithAssignment returns a ChallengeWithAssignment
 func (c *Client) GetChallengeWithAssignment(id string, parseAssignmentVariables ...bool) (ch ChallengeWithAssignment, err error) {
+	return c.GetChallengeWithAssignmentCtx(c.context(), id, parseAssignmentVariables...)
+}
+
+// GetChallengeWithAssignmentCtx is like GetChallengeWithAssignment but uses the given context instead of the client's Context.
+func (c *Client) GetChallengeWithAssignmentCtx(ctx context.Context, id string, parseAssignmentVariables ...bool) (ch ChallengeWithAssignment, err error) {
 	if id == "" {
 		return ch, nil
 	}
@@ -166,7 +187,7 @@ func (c *Client) GetChallengeWithAssignment(id string, parseAssignmentVariables
 		"parseAssignmentVariables": graphql.Boolean(parseVariables),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetChallengeWithAssignment", &q, variables); err != nil {
 		return ch, err
 	}
 
@@ -175,6 +196,11 @@ func (c *Client) GetChallengeWithAssignment(id string, parseAssignmentVariables
 
 // GetUserChallengeWithAssignment returns a ChallengeWithAssignment scoped to a user.
 func (c *Client) GetUserChallengeWithAssignment(userId string, id string, parseAssignmentVariables ...bool) (ch ChallengeWithAssignment, err error) {
+	return c.GetUserChallengeWithAssignmentCtx(c.context(), userId, id, parseAssignmentVariables...)
+}
+
+// GetUserChallengeWithAssignmentCtx is like GetUserChallengeWithAssignment but uses the given context instead of the client's Context.
+func (c *Client) GetUserChallengeWithAssignmentCtx(ctx context.Context, userId string, id string, parseAssignmentVariables ...bool) (ch ChallengeWithAssignment, err error) {
 	if id == "" {
 		return ch, nil
 	}
@@ -193,7 +219,7 @@ func (c *Client) GetUserChallengeWithAssignment(userId string, id string, parseA
 		"parseAssignmentVariables": graphql.Boolean(parseVariables),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetUserChallengeWithAssignment", &q, variables); err != nil {
 		return ch, err
 	}
 
@@ -210,6 +236,11 @@ func (c *Client) GetUserChallengeWithAssignment(userId string, id string, parseA
 // Returns:
 //   - error: Any error encountered while performing the skip operation.
 func (c *Client) SkipToChallenge(userId string, trackId string, id string) (err error) {
+	return c.SkipToChallengeCtx(c.context(), userId, trackId, id)
+}
+
+// SkipToChallengeCtx is like SkipToChallenge but uses the given context instead of the client's Context.
+func (c *Client) SkipToChallengeCtx(ctx context.Context, userId string, trackId string, id string) (err error) {
 	var m struct {
 		SkipToChallenge struct {
 			Id     graphql.String
@@ -223,9 +254,403 @@ func (c *Client) SkipToChallenge(userId string, trackId string, id string) (err
 		"userID":      graphql.String(userId),
 	}
 
-	if err := c.GraphQLClient.Mutate(c.Context, &m, variables); err != nil {
+	if err := c.mutate(ctx, "SkipToChallenge", &m, variables); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// startChallengeMutation represents the GraphQL mutation to start a challenge for a user.
+type startChallengeMutation struct {
+	StartChallenge Challenge `graphql:"startChallenge(trackID: $trackID, challengeID: $challengeID, userID: $userID)"`
+}
+
+// checkChallengeMutation represents the GraphQL mutation to run the check
+// scripts of a challenge for a user.
+type checkChallengeMutation struct {
+	CheckChallenge Challenge `graphql:"checkChallenge(trackID: $trackID, challengeID: $challengeID, userID: $userID)"`
+}
+
+// solveChallengeMutation represents the GraphQL mutation to run the solve
+// scripts of a challenge for a user.
+type solveChallengeMutation struct {
+	SolveChallenge Challenge `graphql:"solveChallenge(trackID: $trackID, challengeID: $challengeID, userID: $userID)"`
+}
+
+// completeChallengeMutation represents the GraphQL mutation to complete a challenge for a user.
+type completeChallengeMutation struct {
+	CompleteChallenge Challenge `graphql:"completeChallenge(trackID: $trackID, challengeID: $challengeID, userID: $userID)"`
+}
+
+// ChallengeCheck is the outcome of running the check scripts of a challenge.
+type ChallengeCheck struct {
+	Challenge Challenge // The challenge after the check, with the attempts of the user.
+	Passed    bool      // Whether the check passed, completing the challenge.
+	Message   string    // The message of the failed attempt if the check did not pass.
+}
+
+// StartChallenge starts a challenge for a user, running its setup scripts.
+//
+// Parameters:
+//   - userId: The unique identifier of the user.
+//   - trackId: The unique identifier of the track.
+//   - id: The unique identifier of the challenge to start.
+//
+// Returns:
+//   - Challenge: The started challenge.
+//   - error: Any error encountered while starting the challenge.
+func (c *Client) StartChallenge(userId string, trackId string, id string) (ch Challenge, err error) {
+	return c.StartChallengeCtx(c.context(), userId, trackId, id)
+}
+
+// StartChallengeCtx is like StartChallenge but uses the given context instead of the client's Context.
+func (c *Client) StartChallengeCtx(ctx context.Context, userId string, trackId string, id string) (ch Challenge, err error) {
+	var m startChallengeMutation
+	if err := c.mutate(ctx, "StartChallenge", &m, userChallengeVariables(userId, trackId, id)); err != nil {
+		return ch, err
+	}
+
+	return m.StartChallenge, nil
+}
+
+// CheckChallenge runs the check scripts of a started challenge for a user.
+// A passing check completes the challenge and unlocks the next one. Each
+// check adds an attempt, carrying the message of the check scripts if it failed.
+//
+// Parameters:
+//   - userId: The unique identifier of the user.
+//   - trackId: The unique identifier of the track.
+//   - id: The unique identifier of the challenge to check.
+//
+// Returns:
+//   - ChallengeCheck: The outcome of the check.
+//   - error: Any error encountered while running the check.
+func (c *Client) CheckChallenge(userId string, trackId string, id string) (check ChallengeCheck, err error) {
+	return c.CheckChallengeCtx(c.context(), userId, trackId, id)
+}
+
+// CheckChallengeCtx is like CheckChallenge but uses the given context instead of the client's Context.
+func (c *Client) CheckChallengeCtx(ctx context.Context, userId string, trackId string, id string) (check ChallengeCheck, err error) {
+	var m checkChallengeMutation
+	if err := c.mutate(ctx, "CheckChallenge", &m, userChallengeVariables(userId, trackId, id)); err != nil {
+		return check, err
+	}
+
+	check.Challenge = m.CheckChallenge
+	check.Passed = m.CheckChallenge.Status == "completed"
+	if !check.Passed && len(m.CheckChallenge.Attempts) > 0 {
+		check.Message = m.CheckChallenge.Attempts[len(m.CheckChallenge.Attempts)-1].Message
+	}
+	return check, nil
+}
+
+// SolveChallenge runs the solve scripts of a started challenge for a user,
+// so that its check passes.
+//
+// Parameters:
+//   - userId: The unique identifier of the user.
+//   - trackId: The unique identifier of the track.
+//   - id: The unique identifier of the challenge to solve.
+//
+// Returns:
+//   - Challenge: The solved challenge.
+//   - error: Any error encountered while solving the challenge.
+func (c *Client) SolveChallenge(userId string, trackId string, id string) (ch Challenge, err error) {
+	return c.SolveChallengeCtx(c.context(), userId, trackId, id)
+}
+
+// SolveChallengeCtx is like SolveChallenge but uses the given context instead of the client's Context.
+func (c *Client) SolveChallengeCtx(ctx context.Context, userId string, trackId string, id string) (ch Challenge, err error) {
+	var m solveChallengeMutation
+	if err := c.mutate(ctx, "SolveChallenge", &m, userChallengeVariables(userId, trackId, id)); err != nil {
+		return ch, err
+	}
+
+	return m.SolveChallenge, nil
+}
+
+// CompleteChallenge completes a started challenge for a user without running
+// its check scripts, unlocking the next one. This is how challenges without
+// checks, such as quizzes, are completed.
+//
+// Parameters:
+//   - userId: The unique identifier of the user.
+//   - trackId: The unique identifier of the track.
+//   - id: The unique identifier of the challenge to complete.
+//
+// Returns:
+//   - Challenge: The completed challenge.
+//   - error: Any error encountered while completing the challenge.
+func (c *Client) CompleteChallenge(userId string, trackId string, id string) (ch Challenge, err error) {
+	return c.CompleteChallengeCtx(c.context(), userId, trackId, id)
+}
+
+// CompleteChallengeCtx is like CompleteChallenge but uses the given context instead of the client's Context.
+func (c *Client) CompleteChallengeCtx(ctx context.Context, userId string, trackId string, id string) (ch Challenge, err error) {
+	var m completeChallengeMutation
+	if err := c.mutate(ctx, "CompleteChallenge", &m, userChallengeVariables(userId, trackId, id)); err != nil {
+		return ch, err
+	}
+
+	return m.CompleteChallenge, nil
+}
+
+// userChallengeVariables returns the variables of the mutations acting on
+// the challenge of a user.
+func userChallengeVariables(userId string, trackId string, id string) map[string]any {
+	return map[string]any{
+		"trackID":     graphql.String(trackId),
+		"challengeID": graphql.String(id),
+		"userID":      graphql.String(userId),
+	}
+}
+
+// ChallengeNote is a note shown to learners while a challenge is loading.
+type ChallengeNote struct {
+	Type     string `json:"type"`     // The type of the note: "text", "image" or "video".
+	Contents string `json:"contents"` // The markdown text, or the URL of the image or video.
+}
+
+// ChallengeInput holds the content of a challenge to create or update.
+// Empty fields are left unchanged by UpdateChallenge.
+type ChallengeInput struct {
+	Id         string          `json:"id,omitempty"`         // The unique identifier of the challenge, set by UpdateChallenge.
+	Slug       string          `json:"slug,omitempty"`       // The slug for the challenge.
+	Title      string          `json:"title,omitempty"`      // The title of the challenge.
+	Teaser     string          `json:"teaser,omitempty"`     // The teaser of the challenge.
+	Type       string          `json:"type,omitempty"`       // The type of the challenge (e.g., "challenge", "quiz").
+	Assignment string          `json:"assignment,omitempty"` // The assignment, in markdown.
+	Timelimit  int             `json:"timelimit,omitempty"`  // The time limit of the challenge, in seconds.
+	Notes      []ChallengeNote `json:"notes,omitempty"`      // The notes shown while the challenge is loading.
+}
+
+// ChallengeScriptAction defines the lifecycle scripts of a challenge.
+type ChallengeScriptAction string
+
+// Constants representing the lifecycle scripts of a challenge.
+const (
+	ChallengeScriptSetup   ChallengeScriptAction = "setup"   // Runs when the challenge starts.
+	ChallengeScriptCheck   ChallengeScriptAction = "check"   // Runs when the learner checks the challenge.
+	ChallengeScriptSolve   ChallengeScriptAction = "solve"   // Runs when the challenge is skipped or solved automatically.
+	ChallengeScriptCleanup ChallengeScriptAction = "cleanup" // Runs when the challenge is completed.
+)
+
+// ChallengeScript is a lifecycle script of a challenge, run on a host.
+type ChallengeScript struct {
+	Host     string                // The hostname the script runs on.
+	Action   ChallengeScriptAction // The lifecycle action of the script.
+	Contents string                // The contents of the script.
+}
+
+// createChallengeMutation represents the GraphQL mutation to create a challenge.
+type createChallengeMutation struct {
+	CreateChallenge Challenge `graphql:"createChallenge(trackID: $trackID, challenge: $challenge)"`
+}
+
+// updateChallengeMutation represents the GraphQL mutation to update a challenge.
+type updateChallengeMutation struct {
+	UpdateChallenge Challenge `graphql:"updateChallenge(challenge: $challenge)"`
+}
+
+// reorderChallengesMutation represents the GraphQL mutation to reorder the
+// challenges of a track.
+type reorderChallengesMutation struct {
+	ReorderChallenges []Challenge `graphql:"reorderChallenges(trackID: $trackID, challengeIDs: $challengeIDs)"`
+}
+
+// deleteChallengeMutation represents the GraphQL mutation to delete a challenge.
+type deleteChallengeMutation struct {
+	DeleteChallenge bool `graphql:"deleteChallenge(challengeID: $challengeID)"`
+}
+
+// setChallengeScriptMutation represents the GraphQL mutation to set a
+// lifecycle script of a challenge.
+type setChallengeScriptMutation struct {
+	SetChallengeScript ChallengeScript `graphql:"setChallengeScript(challengeID: $challengeID, host: $host, action: $action, contents: $contents)"`
+}
+
+// CreateChallenge adds a challenge at the end of a track.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track.
+//   - input: The content of the challenge. Slug and Title are required.
+//
+// Returns:
+//   - Challenge: The created challenge.
+//   - error: Any error encountered while creating the challenge.
+func (c *Client) CreateChallenge(trackId string, input ChallengeInput) (ch Challenge, err error) {
+	return c.CreateChallengeCtx(c.context(), trackId, input)
+}
+
+// CreateChallengeCtx is like CreateChallenge but uses the given context instead of the client's Context.
+func (c *Client) CreateChallengeCtx(ctx context.Context, trackId string, input ChallengeInput) (ch Challenge, err error) {
+	if trackId == "" || input.Slug == "" || input.Title == "" {
+		return ch, fmt.Errorf("%w: a challenge needs a track ID, a slug and a title", ErrValidation)
+	}
+	input.Id = ""
+
+	var m createChallengeMutation
+	variables := map[string]interface{}{
+		"trackID":   graphql.String(trackId),
+		"challenge": input,
+	}
+
+	if err := c.mutate(ctx, "CreateChallenge", &m, variables); err != nil {
+		return ch, err
+	}
+	c.InvalidateCache(CacheChallenges)
+
+	return m.CreateChallenge, nil
+}
+
+// UpdateChallenge updates the content of a challenge. Fields left empty in
+// the input are not changed.
+//
+// Parameters:
+//   - id: The unique identifier of the challenge to update.
+//   - input: The content to set on the challenge.
+//
+// Returns:
+//   - Challenge: The updated challenge.
+//   - error: Any error encountered while updating the challenge.
+func (c *Client) UpdateChallenge(id string, input ChallengeInput) (ch Challenge, err error) {
+	return c.UpdateChallengeCtx(c.context(), id, input)
+}
+
+// UpdateChallengeCtx is like UpdateChallenge but uses the given context instead of the client's Context.
+func (c *Client) UpdateChallengeCtx(ctx context.Context, id string, input ChallengeInput) (ch Challenge, err error) {
+	if id == "" {
+		return ch, fmt.Errorf("%w: missing challenge ID", ErrValidation)
+	}
+	input.Id = id
+
+	var m updateChallengeMutation
+	variables := map[string]interface{}{
+		"challenge": input,
+	}
+
+	if err := c.mutate(ctx, "UpdateChallenge", &m, variables); err != nil {
+		return ch, err
+	}
+	c.InvalidateCache(CacheChallenges)
+
+	return m.UpdateChallenge, nil
+}
+
+// ReorderChallenges sets the order of the challenges of a track.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track.
+//   - ids: The identifiers of all challenges of the track, in their new order.
+//
+// Returns:
+//   - []Challenge: The challenges of the track, in their new order.
+//   - error: Any error encountered while reordering the challenges.
+func (c *Client) ReorderChallenges(trackId string, ids []string) (ch []Challenge, err error) {
+	return c.ReorderChallengesCtx(c.context(), trackId, ids)
+}
+
+// ReorderChallengesCtx is like ReorderChallenges but uses the given context instead of the client's Context.
+func (c *Client) ReorderChallengesCtx(ctx context.Context, trackId string, ids []string) (ch []Challenge, err error) {
+	if trackId == "" {
+		return ch, fmt.Errorf("%w: missing track ID", ErrValidation)
+	}
+
+	challengeIDs := make([]graphql.String, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			return ch, fmt.Errorf("%w: missing challenge ID at position %d", ErrValidation, i)
+		}
+		challengeIDs[i] = graphql.String(id)
+	}
+
+	var m reorderChallengesMutation
+	variables := map[string]interface{}{
+		"trackID":      graphql.String(trackId),
+		"challengeIDs": challengeIDs,
+	}
+
+	if err := c.mutate(ctx, "ReorderChallenges", &m, variables); err != nil {
+		return ch, err
+	}
+	c.InvalidateCache(CacheChallenges)
+
+	return m.ReorderChallenges, nil
+}
+
+// DeleteChallenge deletes a challenge from its track.
+//
+// Parameters:
+//   - id: The unique identifier of the challenge to delete.
+//
+// Returns:
+//   - error: Any error encountered while deleting the challenge.
+func (c *Client) DeleteChallenge(id string) error {
+	return c.DeleteChallengeCtx(c.context(), id)
+}
+
+// DeleteChallengeCtx is like DeleteChallenge but uses the given context instead of the client's Context.
+func (c *Client) DeleteChallengeCtx(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: missing challenge ID", ErrValidation)
+	}
+
+	var m deleteChallengeMutation
+	variables := map[string]interface{}{
+		"challengeID": graphql.String(id),
+	}
+
+	if err := c.mutate(ctx, "DeleteChallenge", &m, variables); err != nil {
 		return err
 	}
+	c.InvalidateCache(CacheChallenges)
+
+	if !m.DeleteChallenge {
+		return fmt.Errorf("failed to delete challenge %s", id)
+	}
+	return nil
+}
+
+// SetChallengeScript uploads a lifecycle script of a challenge for a host,
+// replacing any previous script for the same host and action. An empty
+// script removes it.
+//
+// Parameters:
+//   - id: The unique identifier of the challenge.
+//   - script: The script, along with its host and action.
+//
+// Returns:
+//   - error: Any error encountered while uploading the script.
+func (c *Client) SetChallengeScript(id string, script ChallengeScript) error {
+	return c.SetChallengeScriptCtx(c.context(), id, script)
+}
+
+// SetChallengeScriptCtx is like SetChallengeScript but uses the given context instead of the client's Context.
+func (c *Client) SetChallengeScriptCtx(ctx context.Context, id string, script ChallengeScript) error {
+	if id == "" {
+		return fmt.Errorf("%w: missing challenge ID", ErrValidation)
+	}
+	switch script.Action {
+	case ChallengeScriptSetup, ChallengeScriptCheck, ChallengeScriptSolve, ChallengeScriptCleanup:
+	default:
+		return fmt.Errorf("%w: unknown script action %q", ErrValidation, script.Action)
+	}
+	if script.Host == "" {
+		return fmt.Errorf("%w: missing script host", ErrValidation)
+	}
 
+	var m setChallengeScriptMutation
+	variables := map[string]interface{}{
+		"challengeID": graphql.String(id),
+		"host":        graphql.String(script.Host),
+		"action":      script.Action,
+		"contents":    graphql.String(script.Contents),
+	}
+
+	if err := c.mutate(ctx, "SetChallengeScript", &m, variables); err != nil {
+		return err
+	}
+	c.InvalidateCache(CacheChallenges)
 	return nil
 }
@@ -254,3 +254,240 @@ func TestSkipToChallenge_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "graphql mutation error")
 	mockClient.AssertExpectations(t)
 }
+
+func TestCreateChallenge(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	input := ChallengeInput{
+		Slug:       "intro",
+		Title:      "Introduction",
+		Type:       "challenge",
+		Assignment: "# Welcome",
+		Timelimit:  600,
+		Notes:      []ChallengeNote{{Type: "text", Contents: "Loading..."}},
+	}
+	mockClient.On("Mutate", mock.Anything, &createChallengeMutation{}, map[string]interface{}{
+		"trackID":   graphql.String("track-123"),
+		"challenge": input,
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*createChallengeMutation)
+		m.CreateChallenge = Challenge{Id: "challenge-123", Slug: "intro", Index: 2}
+	}).Return(nil)
+
+	ch, err := client.CreateChallenge("track-123", input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "challenge-123", ch.Id)
+	assert.Equal(t, 2, ch.Index)
+	mockClient.AssertExpectations(t)
+}
+
+func TestUpdateChallenge(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &updateChallengeMutation{}, map[string]interface{}{
+		"challenge": ChallengeInput{Id: "challenge-123", Teaser: "Updated"},
+	}).Return(nil)
+
+	_, err := client.UpdateChallenge("challenge-123", ChallengeInput{Teaser: "Updated"})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestReorderChallenges(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &reorderChallengesMutation{}, map[string]interface{}{
+		"trackID":      graphql.String("track-123"),
+		"challengeIDs": []graphql.String{"ch-2", "ch-1"},
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*reorderChallengesMutation)
+		m.ReorderChallenges = []Challenge{{Id: "ch-2", Index: 0}, {Id: "ch-1", Index: 1}}
+	}).Return(nil)
+
+	challenges, err := client.ReorderChallenges("track-123", []string{"ch-2", "ch-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ch-2", challenges[0].Id)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteChallenge_NotDeleted(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &deleteChallengeMutation{}, mock.Anything).Return(nil)
+
+	err := client.DeleteChallenge("challenge-123")
+
+	assert.EqualError(t, err, "failed to delete challenge challenge-123")
+}
+
+func TestChallengeMutations_MissingID(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	_, err := client.ReorderChallenges("", []string{"ch-1"})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = client.ReorderChallenges("track-123", []string{"ch-1", ""})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	err = client.DeleteChallenge("")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	err = client.SetChallengeScript("", ChallengeScript{Host: "server", Action: ChallengeScriptCheck})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	mockClient.AssertNotCalled(t, "Mutate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetChallengeScript(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &setChallengeScriptMutation{}, map[string]interface{}{
+		"challengeID": graphql.String("challenge-123"),
+		"host":        graphql.String("server"),
+		"action":      ChallengeScriptCheck,
+		"contents":    graphql.String("#!/bin/bash\ntest -f /tmp/done"),
+	}).Return(nil)
+
+	err := client.SetChallengeScript("challenge-123", ChallengeScript{
+		Host:     "server",
+		Action:   ChallengeScriptCheck,
+		Contents: "#!/bin/bash\ntest -f /tmp/done",
+	})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	err = client.SetChallengeScript("challenge-123", ChallengeScript{Host: "server", Action: "teardown"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestSetChallengeScript_InvalidatesCache(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Mutate", mock.Anything, &setChallengeScriptMutation{}, mock.Anything).Return(nil)
+
+	_, _ = client.GetChallenges("track-123")
+	err := client.SetChallengeScript("challenge-123", ChallengeScript{Host: "server", Action: ChallengeScriptCheck})
+	assert.NoError(t, err)
+	_, _ = client.GetChallenges("track-123")
+
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestStartChallenge(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &startChallengeMutation{}, map[string]interface{}{
+		"trackID":     graphql.String("track-123"),
+		"challengeID": graphql.String("challenge-123"),
+		"userID":      graphql.String("user-123"),
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*startChallengeMutation)
+		m.StartChallenge = Challenge{Id: "challenge-123", Status: "started"}
+	}).Return(nil)
+
+	ch, err := client.StartChallenge("user-123", "track-123", "challenge-123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "started", ch.Status)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCheckChallenge(t *testing.T) {
+	tests := map[string]struct {
+		challenge Challenge
+		passed    bool
+		message   string
+	}{
+		"passed": {
+			challenge: Challenge{Id: "challenge-123", Status: "completed"},
+			passed:    true,
+		},
+		"failed": {
+			challenge: Challenge{Id: "challenge-123", Status: "started", Attempts: []ChallengeAttempt{
+				{Message: "nginx is not installed"},
+				{Message: "nginx is not running"},
+			}},
+			message: "nginx is not running",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := new(MockGraphQLClient)
+			client := &Client{
+				GraphQLClient: mockClient,
+			}
+
+			mockClient.On("Mutate", mock.Anything, &checkChallengeMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+				m := args.Get(1).(*checkChallengeMutation)
+				m.CheckChallenge = tt.challenge
+			}).Return(nil)
+
+			check, err := client.CheckChallenge("user-123", "track-123", "challenge-123")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.passed, check.Passed)
+			assert.Equal(t, tt.message, check.Message)
+			assert.Equal(t, tt.challenge, check.Challenge)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSolveChallenge_Error(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &solveChallengeMutation{}, mock.Anything).Return(errors.New("challenge is not started"))
+
+	_, err := client.SolveChallenge("user-123", "track-123", "challenge-123")
+
+	assert.ErrorContains(t, err, "challenge is not started")
+	mockClient.AssertExpectations(t)
+}
+
+func TestCompleteChallenge(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &completeChallengeMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*completeChallengeMutation)
+		m.CompleteChallenge = Challenge{Id: "challenge-123", Status: "completed"}
+	}).Return(nil)
+
+	ch, err := client.CompleteChallenge("user-123", "track-123", "challenge-123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", ch.Status)
+	mockClient.AssertExpectations(t)
+}
@@ -17,14 +17,20 @@ package instruqt
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
-
-	loghttp "github.com/motemen/go-loghttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultBaseURL is the Instruqt GraphQL API endpoint used when no other
+// base URL is configured.
+const DefaultBaseURL = "https://play.instruqt.com/graphql"
+
 // GraphQLClient is an interface that defines the methods for interacting with
 // a GraphQL API, including querying and mutating data.
 type GraphQLClient interface {
@@ -37,10 +43,112 @@ type GraphQLClient interface {
 // and the team slug to identify which team's data to interact with.
 type Client struct {
 	GraphQLClient GraphQLClient   // The GraphQL client used to execute queries and mutations.
-	InfoLogger    *log.Logger     // Logger for informational messages.
-	DebugLogger   *log.Logger     // Logger for debug messages.
+	Logger        *slog.Logger    // Structured logger for operations and debug output.
 	TeamSlug      string          // The slug identifier for the team within Instruqt.
 	Context       context.Context // Default context for API requests
+
+	// Deprecated: Use Logger instead. InfoLogger is only used when Logger is
+	// nil and InfoLogger was changed from the one set by NewClient.
+	InfoLogger *log.Logger
+	// Deprecated: Use Logger instead. DebugLogger is no longer used.
+	DebugLogger *log.Logger
+
+	tracer  trace.Tracer   // Tracer for GraphQL calls, nil when tracing is disabled.
+	metrics *clientMetrics // Instruments for GraphQL calls, nil when metrics are disabled.
+	cache   *responseCache // Cache for read queries, nil when caching is disabled.
+
+	defaultInfoLogger *log.Logger // The InfoLogger set by NewClient, which does not enable logging.
+}
+
+// ClientOption defines a functional option for configuring a Client at
+// construction time. Unlike Option, which modifies a single method call,
+// a ClientOption applies to every request made by the client.
+type ClientOption func(*clientOptions)
+
+// clientOptions holds the settings used by NewClientWithOptions to build
+// the HTTP and GraphQL clients.
+type clientOptions struct {
+	baseURL      string
+	httpClient   *http.Client
+	roundTripper http.RoundTripper
+	userAgent    string
+	timeout      time.Duration
+	infoLogger   *log.Logger
+	debugLogger  *log.Logger
+	logger       *slog.Logger
+	bodyLogging  bool
+	retryPolicy  *RetryPolicy
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+
+	cache     Cache
+	cacheTTLs map[CacheEntity]time.Duration
+}
+
+// WithBaseURL sets the GraphQL endpoint the client talks to, such as a local
+// stand-in server, a proxy or a regional endpoint.
+// Usage: NewClientWithOptions(token, teamSlug, WithBaseURL("http://localhost:8080/graphql"))
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *clientOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the http.Client used to reach the API. The client is
+// copied, and its transport is wrapped so that the bearer token is still
+// added to every request.
+// Usage: NewClientWithOptions(token, teamSlug, WithHTTPClient(httpClient))
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithRoundTripper sets the transport used for HTTP requests. It takes
+// precedence over the transport of a client passed to WithHTTPClient.
+// Usage: NewClientWithOptions(token, teamSlug, WithRoundTripper(transport))
+func WithRoundTripper(rt http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		o.roundTripper = rt
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+// Usage: NewClientWithOptions(token, teamSlug, WithUserAgent("my-service/1.0"))
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the default timeout applied to every HTTP request.
+// A zero value means no timeout.
+// Usage: NewClientWithOptions(token, teamSlug, WithTimeout(30*time.Second))
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithInfoLogger sets the logger used for informational messages.
+//
+// Deprecated: Use WithLogger instead.
+// Usage: NewClientWithOptions(token, teamSlug, WithInfoLogger(log.Default()))
+func WithInfoLogger(logger *log.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.infoLogger = logger
+	}
+}
+
+// WithDebugLogger sets the logger used for debug messages.
+//
+// Deprecated: Use WithLogger and WithBodyLogging instead.
+// Usage: NewClientWithOptions(token, teamSlug, WithDebugLogger(log.Default()))
+func WithDebugLogger(logger *log.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.debugLogger = logger
+	}
 }
 
 // NewClient creates a new instance of the Instruqt API client. It initializes
@@ -53,45 +161,144 @@ type Client struct {
 // Returns:
 //   - A pointer to the newly created Client instance.
 func NewClient(token string, teamSlug string) *Client {
+	return NewClientWithOptions(token, teamSlug)
+}
+
+// NewClientWithOptions creates a new instance of the Instruqt API client,
+// configured with the provided client options. Without options, it is
+// equivalent to NewClient.
+//
+// Parameters:
+//   - token: The API token used for authentication with the Instruqt GraphQL API.
+//   - teamSlug: The slug identifier for the team.
+//   - opts: Optional client settings, such as WithBaseURL or WithHTTPClient.
+//
+// Returns:
+//   - A pointer to the newly created Client instance.
+func NewClientWithOptions(token string, teamSlug string, opts ...ClientOption) *Client {
+	o := &clientOptions{
+		baseURL: DefaultBaseURL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	client := &Client{
-		InfoLogger:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime),
-		DebugLogger: log.New(os.Stdout, "DEBUG:", log.Ldate|log.Ltime),
+		Logger:      o.logger,
+		InfoLogger:  o.infoLogger,
+		DebugLogger: o.debugLogger,
 		TeamSlug:    teamSlug,
 		Context:     context.Background(), // Default context
 	}
+	// Keep the deprecated loggers safe to use by callers.
+	if client.InfoLogger == nil {
+		client.InfoLogger = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime)
+		client.defaultInfoLogger = client.InfoLogger
+	}
+	if client.DebugLogger == nil {
+		client.DebugLogger = log.New(os.Stdout, "DEBUG:", log.Ldate|log.Ltime)
+	}
+	if o.tracerProvider != nil {
+		client.tracer = o.tracerProvider.Tracer(instrumentationName)
+	}
+	if o.meterProvider != nil {
+		client.metrics = newClientMetrics(o.meterProvider)
+	}
+	if o.cache != nil {
+		client.cache = newResponseCache(o.cache, o.cacheTTLs, token, o.timeout)
+	}
 
 	httpClient := &http.Client{}
+	if o.httpClient != nil {
+		*httpClient = *o.httpClient
+	}
+	if o.roundTripper != nil {
+		httpClient.Transport = o.roundTripper
+	}
+	if o.timeout > 0 {
+		httpClient.Timeout = o.timeout
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	transport = &retryAfterRoundTripper{
+		Transport: transport,
+	}
+	if o.bodyLogging {
+		transport = &bodyLoggingRoundTripper{
+			Transport: transport,
+			Logger:    client.logger,
+		}
+	}
+	if o.userAgent != "" {
+		transport = &userAgentRoundTripper{
+			Transport: transport,
+			UserAgent: o.userAgent,
+		}
+	}
 	httpClient.Transport = &BearerTokenRoundTripper{
-		Transport: &loghttp.Transport{
-			Transport: httpClient.Transport,
-			/*
-				LogRequest: func(req *http.Request) {
-					b, _ := httputil.DumpRequestOut(req, true)
-					client.DebugLogger.Printf("out body: %s", string(b))
-				},
-				LogResponse: func(resp *http.Response) {
-					b, _ := httputil.DumpResponse(resp, true)
-					client.DebugLogger.Printf("in body: %s", string(b))
-				},
-			*/
-		},
-		Token: token,
-	}
-
-	client.GraphQLClient = graphql.NewClient("https://play.instruqt.com/graphql", httpClient)
+		Transport: transport,
+		Token:     token,
+	}
+
+	client.GraphQLClient = graphql.NewClient(o.baseURL, httpClient)
+	if o.retryPolicy != nil {
+		client.GraphQLClient = NewRetryClient(client.GraphQLClient, *o.retryPolicy)
+	}
 	return client
 }
 
 // WithContext creates a copy of the Client with a new context.
 // This can be used to set specific timeouts or deadlines for API calls.
+// To scope a single call, prefer the Ctx variant of the method, such as
+// GetTrackByIdCtx.
 func (c *Client) WithContext(ctx context.Context) *Client {
 	// Create a new Client instance with the same properties but a different context.
-	return &Client{
-		GraphQLClient: c.GraphQLClient,
-		InfoLogger:    c.InfoLogger,
-		TeamSlug:      c.TeamSlug,
-		Context:       ctx,
+	clone := *c
+	clone.Context = ctx
+	return &clone
+}
+
+// context returns the client's default context, falling back to
+// context.Background when none is set.
+func (c *Client) context() context.Context {
+	if c.Context == nil {
+		return context.Background()
 	}
+	return c.Context
+}
+
+// query executes a GraphQL query on behalf of the named client operation,
+// converting failures into *GraphQLError or *TransportError values. Results
+// of cacheable operations are served from the client's cache, if any.
+func (c *Client) query(ctx context.Context, operation string, q any, variables map[string]any) error {
+	if cached, err := c.cachedQuery(ctx, operation, q, variables); cached {
+		return err
+	}
+	return c.doQuery(ctx, operation, q, variables)
+}
+
+// doQuery executes a GraphQL query against the API, bypassing the cache.
+func (c *Client) doQuery(ctx context.Context, operation string, q any, variables map[string]any) error {
+	ctx, end := c.startCall(ctx, "query", operation, variables)
+	start := time.Now()
+	err := wrapError(operation, c.GraphQLClient.Query(ctx, q, variables))
+	end(err)
+	c.logOperation(ctx, operation, variables, q, time.Since(start), err)
+	return err
+}
+
+// mutate executes a GraphQL mutation on behalf of the named client
+// operation, converting failures into *GraphQLError or *TransportError values.
+func (c *Client) mutate(ctx context.Context, operation string, m any, variables map[string]any) error {
+	ctx, end := c.startCall(ctx, "mutation", operation, variables)
+	start := time.Now()
+	err := wrapError(operation, c.GraphQLClient.Mutate(ctx, m, variables))
+	end(err)
+	c.logOperation(ctx, operation, variables, m, time.Since(start), err)
+	return err
 }
 
 // BearerTokenRoundTripper is a custom HTTP RoundTripper that adds a Bearer token
@@ -113,3 +320,17 @@ func (rt *BearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response,
 	req.Header.Set("Authorization", "Bearer "+rt.Token)
 	return rt.Transport.RoundTrip(req)
 }
+
+// userAgentRoundTripper is an HTTP RoundTripper that sets the User-Agent
+// header on every request.
+type userAgentRoundTripper struct {
+	Transport http.RoundTripper // The underlying transport to use for HTTP requests.
+	UserAgent string            // The value of the User-Agent header.
+}
+
+// RoundTrip sets the User-Agent header before forwarding the request to the
+// underlying transport.
+func (rt *userAgentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", rt.UserAgent)
+	return rt.Transport.RoundTrip(req)
+}
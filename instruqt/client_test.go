@@ -16,8 +16,10 @@ package instruqt
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,6 +74,12 @@ func TestClientWithContext(t *testing.T) {
 
 	// Ensure the original client context remains unchanged
 	assert.Equal(t, context.Background(), client.Context)
+
+	// Ensure the other properties are carried over
+	assert.Equal(t, client.GraphQLClient, clientWithCtx.GraphQLClient)
+	assert.Equal(t, client.InfoLogger, clientWithCtx.InfoLogger)
+	assert.Equal(t, client.DebugLogger, clientWithCtx.DebugLogger)
+	assert.Equal(t, client.TeamSlug, clientWithCtx.TeamSlug)
 }
 
 func TestGraphQLClientQueryWithContext(t *testing.T) {
@@ -138,3 +146,73 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 		StatusCode: http.StatusOK,
 	}, nil
 }
+
+func TestNewClientWithOptions(t *testing.T) {
+	token := "test-token"
+	team := "my-amazing-team"
+
+	var gotAuth, gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotUserAgent = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"team":{"tpgPublicKey":"public-key"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(token, team,
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithUserAgent("instruqt-go-test"),
+		WithTimeout(5*time.Second),
+	)
+
+	key, err := client.GetTPGPublicKey()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "public-key", key)
+	assert.Equal(t, "Bearer "+token, gotAuth, "Authorization header should be correctly set")
+	assert.Equal(t, "instruqt-go-test", gotUserAgent, "User-Agent header should be correctly set")
+	assert.Equal(t, team, client.TeamSlug)
+}
+
+func TestNewClientWithOptions_RoundTripper(t *testing.T) {
+	called := false
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"team":{"tpgPublicKey":"public-key"}}}`)),
+			Request:    req,
+		}, nil
+	})
+	client := NewClientWithOptions("test-token", "test-team",
+		WithBaseURL("http://instruqt.invalid/graphql"),
+		WithRoundTripper(transport),
+	)
+
+	key, err := client.GetTPGPublicKey()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "public-key", key)
+	assert.True(t, called, "Expected RoundTrip to be called on the custom transport")
+}
+
+func TestNewClientWithOptions_HTTPClientNotModified(t *testing.T) {
+	httpClient := &http.Client{}
+	_ = NewClientWithOptions("test-token", "test-team",
+		WithHTTPClient(httpClient),
+		WithTimeout(time.Second),
+	)
+
+	assert.Nil(t, httpClient.Transport, "The provided http.Client transport should not be modified")
+	assert.Zero(t, httpClient.Timeout, "The provided http.Client timeout should not be modified")
+}
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,229 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	graphql "github.com/hasura/go-graphql-client"
+)
+
+// Sentinel errors classifying failed API calls. Use errors.Is to test for
+// them, and errors.As with *GraphQLError or *TransportError to access the
+// details of the failure.
+var (
+	ErrNotFound     = errors.New("instruqt: not found")
+	ErrUnauthorized = errors.New("instruqt: unauthorized")
+	ErrForbidden    = errors.New("instruqt: forbidden")
+	ErrValidation   = errors.New("instruqt: validation failed")
+	ErrRateLimited  = errors.New("instruqt: rate limited")
+	ErrTransport    = errors.New("instruqt: transport failure")
+)
+
+// GraphQLError is returned when the Instruqt API answers a request with
+// GraphQL errors.
+type GraphQLError struct {
+	Operation string // The client operation that issued the request, e.g. "GetTrackById".
+	Message   string // The message of the first GraphQL error.
+	Code      string // The extensions.code of the first GraphQL error, if any.
+	Path      []any  // The path of the first GraphQL error, if any.
+	Kind      error  // The sentinel error classifying the failure, nil if unclassified.
+	Err       error  // The underlying error returned by the GraphQL client.
+}
+
+// Error implements the error interface.
+func (e *GraphQLError) Error() string {
+	return formatError(e.Operation, e.Message, e.Code, e.Path)
+}
+
+// Unwrap returns the underlying error returned by the GraphQL client.
+func (e *GraphQLError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches the given sentinel error.
+func (e *GraphQLError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// TransportError is returned when a request did not yield a GraphQL
+// response, for instance because of a network failure, a non-200 HTTP
+// status or a response that could not be decoded.
+type TransportError struct {
+	Operation  string // The client operation that issued the request, e.g. "GetTrackById".
+	Message    string // A description of the failure.
+	Code       string // The extensions.code set by the GraphQL client, e.g. "request_error".
+	Path       []any  // The GraphQL path, if any.
+	StatusCode int    // The HTTP status code, zero if no response was received.
+	Kind       error  // The sentinel error classifying the failure, nil if unclassified.
+	Err        error  // The underlying error returned by the GraphQL client.
+}
+
+// Error implements the error interface.
+func (e *TransportError) Error() string {
+	return formatError(e.Operation, e.Message, e.Code, e.Path)
+}
+
+// Unwrap returns the underlying error returned by the GraphQL client.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches the given sentinel error. A
+// TransportError always matches ErrTransport.
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport || (e.Kind != nil && target == e.Kind)
+}
+
+// clientErrorCodes are the extensions.code values the GraphQL client sets
+// on errors it generates itself, as opposed to errors returned by the API.
+var clientErrorCodes = map[string]bool{
+	graphql.ErrRequestError:            true,
+	graphql.ErrJsonEncode:              true,
+	graphql.ErrJsonDecode:              true,
+	graphql.ErrGraphQLEncode:           true,
+	graphql.ErrGraphQLDecode:           true,
+	graphql.ErrGraphQLExtensionsDecode: true,
+}
+
+// wrapError converts an error returned by the GraphQL client into a
+// *GraphQLError or a *TransportError for the given operation.
+func wrapError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var gqlErrs graphql.Errors
+	if !errors.As(err, &gqlErrs) || len(gqlErrs) == 0 {
+		return &TransportError{
+			Operation: operation,
+			Message:   err.Error(),
+			Err:       err,
+		}
+	}
+
+	first := gqlErrs[0]
+	code, _ := first.Extensions["code"].(string)
+
+	if clientErrorCodes[code] {
+		e := &TransportError{
+			Operation: operation,
+			Message:   first.Message,
+			Code:      code,
+			Path:      first.Path,
+			Err:       err,
+		}
+		var netErr graphql.NetworkError
+		if errors.As(err, &netErr) {
+			e.StatusCode = netErr.StatusCode()
+			e.Kind = kindFromStatus(e.StatusCode)
+		}
+		return e
+	}
+
+	messages := make([]string, len(gqlErrs))
+	for i, e := range gqlErrs {
+		messages[i] = e.Message
+	}
+
+	return &GraphQLError{
+		Operation: operation,
+		Message:   strings.Join(messages, "; "),
+		Code:      code,
+		Path:      first.Path,
+		Kind:      kindFromCode(code, first.Message),
+		Err:       err,
+	}
+}
+
+// kindFromStatus classifies an HTTP status code.
+func kindFromStatus(status int) error {
+	switch status {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// kindFromCode classifies a GraphQL error by its extensions.code, falling
+// back to its message when the API did not set a code.
+func kindFromCode(code string, message string) error {
+	switch strings.ToUpper(strings.ReplaceAll(code, "-", "_")) {
+	case "NOT_FOUND":
+		return ErrNotFound
+	case "UNAUTHENTICATED", "UNAUTHORIZED":
+		return ErrUnauthorized
+	case "FORBIDDEN", "PERMISSION_DENIED", "ACCESS_DENIED":
+		return ErrForbidden
+	case "BAD_USER_INPUT", "GRAPHQL_VALIDATION_FAILED", "GRAPHQL_PARSE_FAILED", "VALIDATION_ERROR", "INVALID_ARGUMENT":
+		return ErrValidation
+	case "RATE_LIMITED", "TOO_MANY_REQUESTS", "THROTTLED":
+		return ErrRateLimited
+	}
+
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "not found"):
+		return ErrNotFound
+	case strings.Contains(message, "unauthenticated"), strings.Contains(message, "unauthorized"):
+		return ErrUnauthorized
+	case strings.Contains(message, "forbidden"), strings.Contains(message, "permission denied"):
+		return ErrForbidden
+	case strings.Contains(message, "rate limit"):
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// formatError renders the message shared by GraphQLError and TransportError.
+func formatError(operation, message, code string, path []any) string {
+	var b strings.Builder
+	b.WriteString("instruqt: ")
+	if operation != "" {
+		b.WriteString(operation)
+		b.WriteString(": ")
+	}
+	b.WriteString(message)
+
+	var details []string
+	if code != "" {
+		details = append(details, "code "+code)
+	}
+	if len(path) > 0 {
+		parts := make([]string, len(path))
+		for i, p := range path {
+			parts[i] = fmt.Sprint(p)
+		}
+		details = append(details, "path "+strings.Join(parts, "."))
+	}
+	if len(details) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(details, ", "))
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newErrorTestClient returns a client talking to a server that always
+// answers with the given status code and body.
+func newErrorTestClient(t *testing.T, status int, body string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClientWithOptions("test-token", "test-team", WithBaseURL(server.URL))
+}
+
+func TestGraphQLError_NotFound(t *testing.T) {
+	client := newErrorTestClient(t, http.StatusOK, `{"data":null,"errors":[{"message":"track not found","path":["track"],"extensions":{"code":"NOT_FOUND"}}]}`)
+
+	_, err := client.GetTrackById("track-123")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, errors.Is(err, ErrTransport))
+
+	var gqlErr *GraphQLError
+	assert.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, "GetTrackById", gqlErr.Operation)
+	assert.Equal(t, "NOT_FOUND", gqlErr.Code)
+	assert.Equal(t, []any{"track"}, gqlErr.Path)
+	assert.Equal(t, "instruqt: GetTrackById: track not found (code NOT_FOUND, path track)", err.Error())
+}
+
+func TestGraphQLError_Classification(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		kind error
+	}{
+		{"unauthenticated", `{"errors":[{"message":"no","extensions":{"code":"UNAUTHENTICATED"}}]}`, ErrUnauthorized},
+		{"forbidden", `{"errors":[{"message":"no","extensions":{"code":"FORBIDDEN"}}]}`, ErrForbidden},
+		{"validation", `{"errors":[{"message":"bad","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`, ErrValidation},
+		{"rate limited", `{"errors":[{"message":"slow down","extensions":{"code":"RATE_LIMITED"}}]}`, ErrRateLimited},
+		{"message only", `{"errors":[{"message":"Sandbox not found"}]}`, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newErrorTestClient(t, http.StatusOK, tt.body)
+
+			err := client.StopSandbox("sandbox-123")
+
+			assert.ErrorIs(t, err, tt.kind)
+			var gqlErr *GraphQLError
+			assert.ErrorAs(t, err, &gqlErr)
+			assert.Equal(t, "StopSandbox", gqlErr.Operation)
+		})
+	}
+}
+
+func TestTransportError_StatusCodes(t *testing.T) {
+	tests := []struct {
+		status int
+		kind   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrForbidden},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			client := newErrorTestClient(t, tt.status, `{}`)
+
+			now := time.Now()
+			_, _, err := client.GetPlays(now.AddDate(0, 0, -1), now, 10, 0)
+
+			assert.ErrorIs(t, err, tt.kind)
+			assert.ErrorIs(t, err, ErrTransport)
+
+			var transportErr *TransportError
+			assert.ErrorAs(t, err, &transportErr)
+			assert.Equal(t, "GetPlays", transportErr.Operation)
+			assert.Equal(t, tt.status, transportErr.StatusCode)
+			assert.Equal(t, "request_error", transportErr.Code)
+		})
+	}
+}
+
+func TestErrors_WrappedByCompositeMethods(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	cause := errors.New("connection reset")
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Return(cause)
+
+	_, err := client.GetTrackById("track-123", WithChallenges())
+
+	assert.ErrorIs(t, err, cause)
+	var transportErr *TransportError
+	assert.ErrorAs(t, err, &transportErr)
+	assert.Equal(t, "GetChallenges", transportErr.Operation)
+}
@@ -0,0 +1,471 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	trackFile      = "track.yml"     // The name of the track file in an exported track.
+	assignmentFile = "assignment.md" // The name of the assignment file in a challenge directory.
+)
+
+// challengeDirPattern matches the directories of challenges in an exported
+// track, such as "01-getting-started".
+var challengeDirPattern = regexp.MustCompile(`^(\d+)-(.+)$`)
+
+// trackSpec is the content of a track.yml file.
+type trackSpec struct {
+	Slug        string   `yaml:"slug"`
+	Id          string   `yaml:"id,omitempty"`
+	Type        string   `yaml:"type"`
+	Title       string   `yaml:"title"`
+	Teaser      string   `yaml:"teaser,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Icon        string   `yaml:"icon,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Owner       string   `yaml:"owner,omitempty"`
+	Level       string   `yaml:"level,omitempty"`
+	Maintenance bool     `yaml:"maintenance,omitempty"`
+}
+
+// challengeSpec is the front matter of an assignment.md file.
+type challengeSpec struct {
+	Slug   string `yaml:"slug"`
+	Id     string `yaml:"id,omitempty"`
+	Type   string `yaml:"type"`
+	Title  string `yaml:"title"`
+	Teaser string `yaml:"teaser,omitempty"`
+}
+
+// exportedTrack is a track along with its maintenance flag, as stored on disk.
+type exportedTrack struct {
+	Track       Track
+	Maintenance bool
+}
+
+// ExportTrack writes a track, its tags and its challenges along with their
+// assignments to dir, in the on-disk format of the Instruqt CLI: a track.yml
+// file and one NN-challenge-slug/assignment.md file per challenge.
+//
+// Exporting over a previous export updates it in place: challenge
+// directories are renamed when challenges are reordered, and removed along
+// with their content when challenges no longer exist. Other files, such as
+// lifecycle scripts, are kept.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track to export.
+//   - dir: The directory to write the track to. It is created if needed.
+//   - opts: Optional settings, such as WithConcurrency. The export stops at
+//     the first assignment that cannot be fetched, whatever the error
+//     policy, so that a partial track never overwrites a previous export.
+//
+// Returns:
+//   - error: Any error encountered while fetching or writing the track.
+func (c *Client) ExportTrack(trackId string, dir string, opts ...Option) error {
+	return c.ExportTrackCtx(c.context(), trackId, dir, opts...)
+}
+
+// ExportTrackCtx is like ExportTrack but uses the given context instead of the client's Context.
+func (c *Client) ExportTrackCtx(ctx context.Context, trackId string, dir string, opts ...Option) (err error) {
+	ctx, end := c.startOperation(ctx, "ExportTrack", attribute.String("instruqt.trackId", trackId))
+	defer func() { end(err) }()
+
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	track, err := c.GetTrackByIdCtx(ctx, trackId, WithChallenges())
+	if err != nil {
+		return fmt.Errorf("failed to get track %s: %w", trackId, err)
+	}
+
+	err = fanOut(ctx, len(track.Challenges), options.concurrency, ErrorPolicyFailFast, func(ctx context.Context, i int) error {
+		ch := &track.Challenges[i]
+		assignment, err := c.GetChallengeWithAssignmentCtx(ctx, ch.Id)
+		if err != nil {
+			return fmt.Errorf("failed to get assignment for challenge %s: %w", ch.Id, err)
+		}
+		ch.Assignment = assignment.Assignment
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	inMaintenance, err := c.GetTracksInMaintenanceCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tracks in maintenance: %w", err)
+	}
+
+	return writeTrackDir(dir, c.TeamSlug, exportedTrack{
+		Track:       track,
+		Maintenance: slices.Contains(inMaintenance, track.Slug),
+	})
+}
+
+// ImportTrack reads a track exported by ExportTrack from dir and creates it
+// in the client's team, or updates the track with the same slug if it
+// already exists. Challenges are matched by slug: existing ones are updated,
+// new ones created, and those missing from dir deleted, before the
+// challenges are put in the order of their directories. IDs in the exported
+// files are ignored, so that tracks can be moved between teams.
+//
+// Parameters:
+//   - dir: The directory holding the exported track.
+//
+// Returns:
+//   - Track: The imported track, along with its challenges.
+//   - error: Any error encountered while reading or importing the track.
+func (c *Client) ImportTrack(dir string) (t Track, err error) {
+	return c.ImportTrackCtx(c.context(), dir)
+}
+
+// ImportTrackCtx is like ImportTrack but uses the given context instead of the client's Context.
+func (c *Client) ImportTrackCtx(ctx context.Context, dir string) (t Track, err error) {
+	exported, err := readTrackDir(dir)
+	if err != nil {
+		return t, err
+	}
+	spec := exported.Track
+
+	ctx, end := c.startOperation(ctx, "ImportTrack", attribute.String("instruqt.trackSlug", spec.Slug))
+	defer func() { end(err) }()
+
+	tags := make([]string, len(spec.TrackTags))
+	for i, tag := range spec.TrackTags {
+		tags[i] = tag.Value
+	}
+	input := TrackInput{
+		Slug:        spec.Slug,
+		Title:       spec.Title,
+		Teaser:      spec.Teaser,
+		Description: spec.Description,
+		Level:       spec.Level,
+		Icon:        spec.Icon,
+		Tags:        tags,
+		Maintenance: &exported.Maintenance,
+	}
+
+	existing, err := c.GetTrackBySlugCtx(ctx, spec.Slug)
+	switch {
+	case errors.Is(err, ErrNotFound) || (err == nil && existing.Id == ""):
+		t, err = c.CreateTrackCtx(ctx, input)
+	case err == nil:
+		t, err = c.UpdateTrackCtx(ctx, existing.Id, input)
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to import track %s: %w", spec.Slug, err)
+	}
+
+	challenges, err := c.GetChallengesCtx(ctx, t.Id)
+	if err != nil {
+		return t, fmt.Errorf("failed to get challenges for track %s: %w", t.Id, err)
+	}
+	bySlug := make(map[string]Challenge, len(challenges))
+	for _, ch := range challenges {
+		bySlug[ch.Slug] = ch
+	}
+
+	ids := make([]string, len(spec.Challenges))
+	for i, ch := range spec.Challenges {
+		input := ChallengeInput{
+			Slug:       ch.Slug,
+			Title:      ch.Title,
+			Teaser:     ch.Teaser,
+			Type:       ch.Type,
+			Assignment: ch.Assignment,
+		}
+
+		var imported Challenge
+		if current, ok := bySlug[ch.Slug]; ok {
+			imported, err = c.UpdateChallengeCtx(ctx, current.Id, input)
+			delete(bySlug, ch.Slug)
+		} else {
+			imported, err = c.CreateChallengeCtx(ctx, t.Id, input)
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to import challenge %s: %w", ch.Slug, err)
+		}
+		ids[i] = imported.Id
+	}
+
+	for slug, ch := range bySlug {
+		if err := c.DeleteChallengeCtx(ctx, ch.Id); err != nil {
+			return t, fmt.Errorf("failed to delete challenge %s: %w", slug, err)
+		}
+	}
+
+	if len(ids) > 0 {
+		t.Challenges, err = c.ReorderChallengesCtx(ctx, t.Id, ids)
+		if err != nil {
+			return t, fmt.Errorf("failed to reorder challenges of track %s: %w", t.Id, err)
+		}
+	}
+
+	return t, nil
+}
+
+// writeTrackDir writes a track to dir in the on-disk format of the Instruqt CLI.
+func writeTrackDir(dir string, owner string, exported exportedTrack) error {
+	track := exported.Track
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create track directory: %w", err)
+	}
+
+	spec := trackSpec{
+		Slug:        track.Slug,
+		Id:          track.Id,
+		Type:        "track",
+		Title:       track.Title,
+		Teaser:      track.Teaser,
+		Description: track.Description,
+		Icon:        track.Icon,
+		Owner:       owner,
+		Level:       track.Level,
+		Maintenance: exported.Maintenance,
+	}
+	for _, tag := range track.TrackTags {
+		spec.Tags = append(spec.Tags, tag.Value)
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", trackFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, trackFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", trackFile, err)
+	}
+
+	existing, err := challengeDirs(dir)
+	if err != nil {
+		return err
+	}
+
+	challenges := append([]Challenge(nil), track.Challenges...)
+	sort.SliceStable(challenges, func(i, j int) bool { return challenges[i].Index < challenges[j].Index })
+
+	width := max(2, len(strconv.Itoa(len(challenges))))
+	for i, ch := range challenges {
+		name := fmt.Sprintf("%0*d-%s", width, i+1, ch.Slug)
+		path := filepath.Join(dir, name)
+
+		// Keep the other files of a previously exported challenge.
+		if previous, ok := existing[ch.Slug]; ok {
+			delete(existing, ch.Slug)
+			if previous != name {
+				if err := os.Rename(filepath.Join(dir, previous), path); err != nil {
+					return fmt.Errorf("failed to rename challenge directory %s: %w", previous, err)
+				}
+			}
+		}
+
+		if err := writeAssignment(path, ch); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range existing {
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to remove challenge directory %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// writeAssignment writes the assignment.md file of a challenge to dir.
+func writeAssignment(dir string, ch Challenge) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create challenge directory: %w", err)
+	}
+
+	frontMatter, err := yaml.Marshal(challengeSpec{
+		Slug:   ch.Slug,
+		Id:     ch.Id,
+		Type:   ch.Type,
+		Title:  ch.Title,
+		Teaser: ch.Teaser,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode front matter of challenge %s: %w", ch.Slug, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(frontMatter)
+	buf.WriteString("---\n\n")
+	buf.WriteString(strings.TrimLeft(ch.Assignment, "\n"))
+	if !strings.HasSuffix(ch.Assignment, "\n") {
+		buf.WriteString("\n")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, assignmentFile), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write assignment of challenge %s: %w", ch.Slug, err)
+	}
+	return nil
+}
+
+// readTrackDir reads a track written by writeTrackDir.
+func readTrackDir(dir string) (exportedTrack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, trackFile))
+	if err != nil {
+		return exportedTrack{}, fmt.Errorf("failed to read %s: %w", trackFile, err)
+	}
+
+	var spec trackSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return exportedTrack{}, fmt.Errorf("failed to decode %s: %w", trackFile, err)
+	}
+	if spec.Slug == "" || spec.Title == "" {
+		return exportedTrack{}, fmt.Errorf("%w: %s needs a slug and a title", ErrValidation, trackFile)
+	}
+
+	track := Track{
+		Slug:        spec.Slug,
+		Id:          spec.Id,
+		Title:       spec.Title,
+		Teaser:      spec.Teaser,
+		Description: spec.Description,
+		Icon:        spec.Icon,
+		Level:       spec.Level,
+	}
+	for _, tag := range spec.Tags {
+		track.TrackTags = append(track.TrackTags, TrackTag{Value: tag})
+	}
+
+	dirs, err := challengeDirs(dir)
+	if err != nil {
+		return exportedTrack{}, err
+	}
+	names := make([]string, 0, len(dirs))
+	for _, name := range dirs {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := challengeDirIndex(names[i]), challengeDirIndex(names[j])
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	for i, name := range names {
+		ch, err := readAssignment(filepath.Join(dir, name))
+		if err != nil {
+			return exportedTrack{}, err
+		}
+		ch.Index = i
+		track.Challenges = append(track.Challenges, ch)
+	}
+
+	return exportedTrack{Track: track, Maintenance: spec.Maintenance}, nil
+}
+
+// readAssignment reads the assignment.md file of a challenge directory.
+func readAssignment(dir string) (Challenge, error) {
+	path := filepath.Join(dir, assignmentFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Challenge{}, fmt.Errorf("failed to read assignment: %w", err)
+	}
+
+	frontMatter, body, err := splitFrontMatter(string(data))
+	if err != nil {
+		return Challenge{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var spec challengeSpec
+	if err := yaml.Unmarshal([]byte(frontMatter), &spec); err != nil {
+		return Challenge{}, fmt.Errorf("failed to decode front matter of %s: %w", path, err)
+	}
+	if spec.Slug == "" || spec.Title == "" {
+		return Challenge{}, fmt.Errorf("%w: %s needs a slug and a title", ErrValidation, path)
+	}
+
+	return Challenge{
+		Id:         spec.Id,
+		Slug:       spec.Slug,
+		Title:      spec.Title,
+		Teaser:     spec.Teaser,
+		Type:       spec.Type,
+		Assignment: body,
+	}, nil
+}
+
+// splitFrontMatter splits a markdown document into its YAML front matter,
+// delimited by "---" lines, and its body.
+func splitFrontMatter(doc string) (frontMatter string, body string, err error) {
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(doc, "---\n")
+	if !ok {
+		return "", "", errors.New("missing front matter")
+	}
+
+	frontMatter, body, ok = strings.Cut(rest, "\n---\n")
+	if !ok {
+		frontMatter, ok = strings.CutSuffix(rest, "\n---")
+		if !ok {
+			return "", "", errors.New("unterminated front matter")
+		}
+	}
+	return frontMatter, strings.TrimLeft(body, "\n"), nil
+}
+
+// challengeDirs returns the names of the challenge directories in dir, by
+// challenge slug.
+func challengeDirs(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read track directory: %w", err)
+	}
+
+	dirs := map[string]string{}
+	for _, entry := range entries {
+		m := challengeDirPattern.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || m == nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), assignmentFile)); err != nil {
+			continue
+		}
+		dirs[m[2]] = entry.Name()
+	}
+	return dirs, nil
+}
+
+// challengeDirIndex returns the position prefix of a challenge directory name.
+func challengeDirIndex(name string) int {
+	m := challengeDirPattern.FindStringSubmatch(name)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
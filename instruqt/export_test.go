@@ -0,0 +1,121 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExportedTrack() exportedTrack {
+	return exportedTrack{
+		Track: Track{
+			Id:          "track-123",
+			Slug:        "getting-started",
+			Title:       "Getting Started",
+			Teaser:      "Learn the basics",
+			Description: "A longer description.\n\nOn several lines.",
+			Level:       "beginner",
+			TrackTags:   []TrackTag{{Value: "cilium"}, {Value: "ebpf"}},
+			Challenges: []Challenge{
+				{Id: "ch-2", Slug: "install", Title: "Install", Type: "challenge", Index: 1, Assignment: "Run `cilium install`.\n"},
+				{Id: "ch-1", Slug: "intro", Title: "Introduction", Type: "challenge", Index: 0, Assignment: "# Welcome\n\n---\n\nSeparated.\n"},
+			},
+		},
+		Maintenance: true,
+	}
+}
+
+func TestWriteTrackDir(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, writeTrackDir(dir, "isovalent", testExportedTrack()))
+
+	trackYml, err := os.ReadFile(filepath.Join(dir, "track.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(trackYml), "slug: getting-started\n")
+	assert.Contains(t, string(trackYml), "owner: isovalent\n")
+	assert.Contains(t, string(trackYml), "- cilium\n")
+
+	assignment, err := os.ReadFile(filepath.Join(dir, "01-intro", "assignment.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nslug: intro\nid: ch-1\ntype: challenge\ntitle: Introduction\n---\n\n# Welcome\n\n---\n\nSeparated.\n", string(assignment))
+	assert.FileExists(t, filepath.Join(dir, "02-install", "assignment.md"))
+}
+
+func TestReadTrackDir_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	exported := testExportedTrack()
+	require.NoError(t, writeTrackDir(dir, "isovalent", exported))
+
+	read, err := readTrackDir(dir)
+
+	require.NoError(t, err)
+	assert.True(t, read.Maintenance)
+	assert.Equal(t, exported.Track.Description, read.Track.Description)
+	assert.Equal(t, exported.Track.TrackTags, read.Track.TrackTags)
+	require.Len(t, read.Track.Challenges, 2)
+	assert.Equal(t, "intro", read.Track.Challenges[0].Slug)
+	assert.Equal(t, "# Welcome\n\n---\n\nSeparated.\n", read.Track.Challenges[0].Assignment)
+	assert.Equal(t, "install", read.Track.Challenges[1].Slug)
+	assert.Equal(t, 1, read.Track.Challenges[1].Index)
+}
+
+func TestReadTrackDir_SamePrefix(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, writeTrackDir(dir, "isovalent", testExportedTrack()))
+	require.NoError(t, os.Rename(filepath.Join(dir, "02-install"), filepath.Join(dir, "01-install")))
+
+	// Directories with the same prefix are ordered by name.
+	for range 10 {
+		read, err := readTrackDir(dir)
+		require.NoError(t, err)
+		require.Len(t, read.Track.Challenges, 2)
+		assert.Equal(t, "install", read.Track.Challenges[0].Slug)
+		assert.Equal(t, "intro", read.Track.Challenges[1].Slug)
+	}
+}
+
+func TestWriteTrackDir_UpdatesPreviousExport(t *testing.T) {
+	dir := t.TempDir()
+	exported := testExportedTrack()
+	require.NoError(t, writeTrackDir(dir, "isovalent", exported))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "02-install", "check-server"), []byte("exit 0"), 0o644))
+
+	// Move "install" first and drop "intro".
+	exported.Track.Challenges = []Challenge{{Id: "ch-2", Slug: "install", Title: "Install", Index: 0}}
+	require.NoError(t, writeTrackDir(dir, "isovalent", exported))
+
+	assert.FileExists(t, filepath.Join(dir, "01-install", "check-server"), "Expected other files to follow their challenge")
+	assert.NoDirExists(t, filepath.Join(dir, "01-intro"))
+	assert.NoDirExists(t, filepath.Join(dir, "02-install"))
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	frontMatter, body, err := splitFrontMatter("---\r\nslug: intro\r\n---\r\n\r\nBody\r\n")
+	require.NoError(t, err)
+	assert.Equal(t, "slug: intro", frontMatter)
+	assert.Equal(t, "Body\n", body)
+
+	_, _, err = splitFrontMatter("# No front matter")
+	assert.EqualError(t, err, "missing front matter")
+
+	_, _, err = splitFrontMatter("---\nslug: intro\n")
+	assert.EqualError(t, err, "unterminated front matter")
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// defaultConcurrency is the number of requests a method fanning out to
+// several queries runs in parallel, unless overridden with WithConcurrency.
+const defaultConcurrency = 8
+
+// ErrorPolicy defines how methods fanning out to several queries, such as
+// GetTracks with WithChallenges, handle failed queries.
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyFailFast cancels the remaining queries on the first failure
+	// and returns its error. This is the default.
+	ErrorPolicyFailFast ErrorPolicy = iota
+	// ErrorPolicyCollectAll runs every query, returns the partial results
+	// and joins the errors of all failed queries, in order.
+	ErrorPolicyCollectAll
+)
+
+// fanOut calls fn for every index in [0, n), running up to concurrency
+// calls in parallel. Each call writes its result back by index, so results
+// keep their order regardless of completion order.
+//
+// With ErrorPolicyFailFast, the context passed to fn is canceled on the
+// first failure, no further calls are started and the first error is
+// returned. With ErrorPolicyCollectAll, every call is made and the errors
+// are joined in index order.
+func fanOut(ctx context.Context, n int, concurrency int, policy ErrorPolicy, fn func(ctx context.Context, i int) error) error {
+	if n == 0 {
+		return nil
+	}
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	concurrency = min(concurrency, n)
+
+	fanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		first   error
+		errs    = make([]error, n)
+		indices = make(chan int)
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				err := fn(fanCtx, i)
+				if err == nil {
+					continue
+				}
+				errs[i] = err
+				if policy == ErrorPolicyFailFast {
+					once.Do(func() {
+						first = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	// Stop handing out indices once the context is canceled, either by the
+	// caller or by a failure under ErrorPolicyFailFast.
+	var stopped error
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-fanCtx.Done():
+			stopped = ctx.Err()
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return errors.Join(append(errs, stopped)...)
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFanOut_BoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	results := make([]int, 20)
+
+	err := fanOut(context.Background(), len(results), 3, ErrorPolicyFailFast, func(ctx context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		results[i] = i * i
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	for i, r := range results {
+		assert.Equal(t, i*i, r)
+	}
+}
+
+func TestFanOut_FailFast(t *testing.T) {
+	var calls atomic.Int32
+	failure := errors.New("boom")
+
+	err := fanOut(context.Background(), 100, 1, ErrorPolicyFailFast, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i == 2 {
+			return failure
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.Less(t, calls.Load(), int32(100), "Expected remaining calls to be skipped")
+}
+
+func TestFanOut_CollectAll(t *testing.T) {
+	var calls atomic.Int32
+
+	err := fanOut(context.Background(), 10, 4, ErrorPolicyCollectAll, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i%5 == 0 {
+			return fmt.Errorf("item %d failed", i)
+		}
+		return nil
+	})
+
+	assert.EqualError(t, err, "item 0 failed\nitem 5 failed")
+	assert.Equal(t, int32(10), calls.Load())
+}
+
+func TestGetTracks_WithChallengesAndReviews(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}, {Id: "track-3"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		trackID := args.Get(2).(map[string]interface{})["trackId"].(graphql.String)
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: string(trackID) + "-challenge"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		// The reviews query is an anonymous struct.
+		reviews := reflect.ValueOf(args.Get(1)).Elem().FieldByName("TrackReviews")
+		reviews.FieldByName("TotalCount").SetInt(2)
+	}).Return(nil)
+
+	tracks, err := client.GetTracks(WithChallenges(), WithReviews(), WithConcurrency(2))
+
+	assert.NoError(t, err)
+	assert.Len(t, tracks, 3)
+	for _, track := range tracks {
+		// Regression test: the enrichments used to be stored in copies of
+		// the tracks, and never returned.
+		assert.Equal(t, []Challenge{{Id: track.Id + "-challenge"}}, track.Challenges)
+		assert.Equal(t, 2, track.TrackReviews.TotalCount)
+	}
+}
+
+func TestGetTracks_CollectAllReturnsPartialResults(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["trackId"] == graphql.String("track-1")
+	})).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "challenge-1"}}
+	}).Return(nil)
+
+	tracks, err := client.GetTracks(WithChallenges(), WithErrorPolicy(ErrorPolicyCollectAll))
+
+	assert.ErrorContains(t, err, "failed to fetch challenges for track track-1")
+	assert.Len(t, tracks, 2)
+	assert.Empty(t, tracks[0].Challenges)
+	assert.Equal(t, []Challenge{{Id: "challenge-1"}}, tracks[1].Challenges)
+
+	tracks, err = client.GetTracks(WithChallenges())
+	assert.Error(t, err)
+	assert.Nil(t, tracks)
+}
+
+func TestGetTracksEnrichment(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}, {Id: "track-3"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["trackId"] == graphql.String("track-2")
+	})).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		trackID := args.Get(2).(map[string]interface{})["trackId"].(graphql.String)
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: string(trackID) + "-challenge"}}
+	}).Return(nil)
+
+	// The error policy is ignored: one broken track never fails the list.
+	enrichment, err := client.GetTracksEnrichment(WithChallenges(), WithErrorPolicy(ErrorPolicyFailFast))
+
+	assert.NoError(t, err)
+	assert.Len(t, enrichment.Tracks, 3)
+	assert.Equal(t, []Challenge{{Id: "track-1-challenge"}}, enrichment.Tracks[0].Challenges)
+	assert.Empty(t, enrichment.Tracks[1].Challenges)
+	assert.Equal(t, []Challenge{{Id: "track-3-challenge"}}, enrichment.Tracks[2].Challenges)
+	assert.Len(t, enrichment.Errors, 1)
+	assert.ErrorContains(t, enrichment.Errors["track-2"], "failed to fetch challenges for track track-2")
+	assert.ErrorContains(t, enrichment.Err(), "graphql error")
+}
+
+func TestGetTracksEnrichment_WithBatchSize(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}, {Id: "track-3"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["id0"] == graphql.String("track-3")
+	})).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	enrichment, err := client.GetTracksEnrichment(WithChallenges(), WithBatchSize(2))
+
+	assert.NoError(t, err)
+	assert.Len(t, enrichment.Tracks, 3)
+	assert.Equal(t, []string{"track-3"}, slices.Collect(maps.Keys(enrichment.Errors)), "Expected only the failed chunk to be reported")
+}
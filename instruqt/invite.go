@@ -15,9 +15,11 @@
 package instruqt
 
 import (
+	"context"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // inviteQuery represents the GraphQL query structure for retrieving a single
@@ -93,16 +95,24 @@ type variable struct {
 //   - TrackInvite: The track invite details if found.
 //   - error: Any error encountered while retrieving the invite.
 func (c *Client) GetInvite(inviteId string, opts ...Option) (i TrackInvite, err error) {
+	return c.GetInviteCtx(c.context(), inviteId, opts...)
+}
+
+// GetInviteCtx is like GetInvite but uses the given context instead of the client's Context.
+func (c *Client) GetInviteCtx(ctx context.Context, inviteId string, opts ...Option) (i TrackInvite, err error) {
 	if inviteId == "" {
 		return i, nil
 	}
 
+	ctx, end := c.startOperation(ctx, "GetInvite", attribute.String("instruqt.inviteId", inviteId))
+	defer func() { end(err) }()
+
 	variables := map[string]interface{}{
 		"inviteId": graphql.String(inviteId),
 	}
 
 	var q inviteQuery
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetInvite", &q, variables); err != nil {
 		return i, err
 	}
 
@@ -111,7 +121,7 @@ func (c *Client) GetInvite(inviteId string, opts ...Option) (i TrackInvite, err
 		opt(options)
 	}
 	if options.includeTracks {
-		tracks, err := c.GetInviteTracks(inviteId)
+		tracks, err := c.GetInviteTracksCtx(ctx, inviteId)
 		if err != nil {
 			return i, err
 		}
@@ -130,6 +140,11 @@ func (c *Client) GetInvite(inviteId string, opts ...Option) (i TrackInvite, err
 //   - []Track: The tracks associated with the invite.
 //   - error: Any error encountered while retrieving the invite tracks.
 func (c *Client) GetInviteTracks(inviteId string) ([]Track, error) {
+	return c.GetInviteTracksCtx(c.context(), inviteId)
+}
+
+// GetInviteTracksCtx is like GetInviteTracks but uses the given context instead of the client's Context.
+func (c *Client) GetInviteTracksCtx(ctx context.Context, inviteId string) ([]Track, error) {
 	if inviteId == "" {
 		return nil, nil
 	}
@@ -138,7 +153,7 @@ func (c *Client) GetInviteTracks(inviteId string) ([]Track, error) {
 	variables := map[string]interface{}{
 		"inviteId": graphql.String(inviteId),
 	}
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetInviteTracks", &q, variables); err != nil {
 		return nil, err
 	}
 
@@ -161,12 +176,20 @@ type invitesTracksQuery struct {
 //   - []TrackInvite: A list of track invites for the team.
 //   - error: Any error encountered while retrieving the invites.
 func (c *Client) GetInvites(opts ...Option) (i []TrackInvite, err error) {
+	return c.GetInvitesCtx(c.context(), opts...)
+}
+
+// GetInvitesCtx is like GetInvites but uses the given context instead of the client's Context.
+func (c *Client) GetInvitesCtx(ctx context.Context, opts ...Option) (i []TrackInvite, err error) {
+	ctx, end := c.startOperation(ctx, "GetInvites")
+	defer func() { end(err) }()
+
 	variables := map[string]interface{}{
 		"teamSlug": graphql.String(c.TeamSlug),
 	}
 
 	var q invitesQuery
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetInvites", &q, variables); err != nil {
 		return i, err
 	}
 
@@ -175,7 +198,7 @@ func (c *Client) GetInvites(opts ...Option) (i []TrackInvite, err error) {
 		opt(options)
 	}
 	if options.includeTracks {
-		tracksByInvite, err := c.GetInvitesTracks()
+		tracksByInvite, err := c.GetInvitesTracksCtx(ctx)
 		if err != nil {
 			return i, err
 		}
@@ -193,11 +216,16 @@ func (c *Client) GetInvites(opts ...Option) (i []TrackInvite, err error) {
 //   - map[string][]Track: A map of invite ID to associated tracks.
 //   - error: Any error encountered while retrieving invite tracks.
 func (c *Client) GetInvitesTracks() (map[string][]Track, error) {
+	return c.GetInvitesTracksCtx(c.context())
+}
+
+// GetInvitesTracksCtx is like GetInvitesTracks but uses the given context instead of the client's Context.
+func (c *Client) GetInvitesTracksCtx(ctx context.Context) (map[string][]Track, error) {
 	var q invitesTracksQuery
 	variables := map[string]interface{}{
 		"teamSlug": graphql.String(c.TeamSlug),
 	}
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetInvitesTracks", &q, variables); err != nil {
 		return nil, err
 	}
 
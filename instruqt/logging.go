@@ -0,0 +1,330 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redacted replaces sensitive values in debug logs.
+const redacted = "[REDACTED]"
+
+// piiFields lists the JSON keys, in lower case, whose values are redacted
+// from logged request and response bodies. See IsPIIField.
+var piiFields = map[string]bool{
+	"email":                 true,
+	"firstname":             true,
+	"lastname":              true,
+	"display_name":          true,
+	"companyname":           true,
+	"jobtitle":              true,
+	"allowedemailaddresses": true,
+	"userdetails":           true,
+}
+
+// IsPIIField reports whether a JSON key of the API holds personal
+// information, such as a name or an email address. Keys are compared case
+// insensitively. The client redacts these fields from logged bodies.
+//
+// Parameters:
+//   - key: The JSON key.
+//
+// Returns:
+//   - bool: Whether the value of the key is personal information.
+func IsPIIField(key string) bool {
+	return piiFields[strings.ToLower(key)]
+}
+
+// WithLogger sets the structured logger used by the client. Every API
+// operation is logged at debug level, failed operations at warn level.
+// Usage: NewClientWithOptions(token, teamSlug, WithLogger(slog.New(slog.NewJSONHandler(os.Stderr, nil))))
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithBodyLogging logs the HTTP requests and responses exchanged with the
+// API, including their bodies, at debug level. The bearer token and
+// personal information such as names and email addresses are redacted.
+// Usage: NewClientWithOptions(token, teamSlug, WithBodyLogging())
+func WithBodyLogging() ClientOption {
+	return func(o *clientOptions) {
+		o.bodyLogging = true
+	}
+}
+
+// logger returns the structured logger of the client. Clients without a
+// Logger fall back to the deprecated InfoLogger if it was set by the
+// caller, and otherwise discard their logs.
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if c.InfoLogger != nil && c.InfoLogger != c.defaultInfoLogger {
+		return slog.New(slog.NewTextHandler(c.InfoLogger.Writer(), nil))
+	}
+	return discardLogger
+}
+
+// logOperation emits a structured record describing a GraphQL call made on
+// behalf of a client operation.
+func (c *Client) logOperation(ctx context.Context, operation string, variables map[string]any, result any, duration time.Duration, err error) {
+	logger := c.logger()
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", operation),
+		slog.String("team_slug", c.TeamSlug),
+		slog.Duration("duration", duration),
+	}
+	if ids := identifiers(variables); len(ids) > 0 {
+		attrs = append(attrs, slog.Group("ids", ids...))
+	}
+	if err != nil {
+		attrs = append(attrs,
+			slog.String("error_class", errorClass(err)),
+			slog.String("error", err.Error()),
+		)
+		logger.LogAttrs(ctx, level, "instruqt operation failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, slog.Int("result_size", resultSize(result)))
+	logger.LogAttrs(ctx, level, "instruqt operation", attrs...)
+}
+
+// identifiers extracts the ID and slug variables of a GraphQL call, which
+// identify the entities involved without exposing other input.
+func identifiers(variables map[string]any) []any {
+	keys := identifierKeys(variables)
+	attrs := make([]any, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.String(key, fmt.Sprint(variables[key])))
+	}
+	return attrs
+}
+
+// identifierKeys returns the sorted names of the ID and slug variables of a
+// GraphQL call, including numbered ones such as those of batched queries.
+func identifierKeys(variables map[string]any) []string {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		lower := strings.TrimRight(strings.ToLower(key), "0123456789")
+		if strings.HasSuffix(lower, "id") || strings.HasSuffix(lower, "ids") || strings.HasSuffix(lower, "slug") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// errorClass returns a short, stable label classifying err.
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrForbidden):
+		return "forbidden"
+	case errors.Is(err, ErrValidation):
+		return "validation"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.Is(err, ErrTransport):
+		return "transport"
+	}
+
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) {
+		return "graphql"
+	}
+	return "unknown"
+}
+
+// resultSize returns the number of items decoded into a GraphQL query
+// result: the length of a list selected at the top level, or of its Nodes
+// or Items, and 1 for single entities.
+func resultSize(result any) int {
+	v := reflect.ValueOf(result)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 1
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Slice:
+			return field.Len()
+		case reflect.Struct:
+			for _, name := range []string{"Nodes", "Items"} {
+				if list := field.FieldByName(name); list.IsValid() && list.Kind() == reflect.Slice {
+					return list.Len()
+				}
+			}
+		}
+	}
+	return 1
+}
+
+// bodyLoggingRoundTripper is an HTTP RoundTripper that logs requests and
+// responses, including their bodies, with sensitive data redacted.
+type bodyLoggingRoundTripper struct {
+	Transport http.RoundTripper // The underlying transport to use for HTTP requests.
+	Logger    func() *slog.Logger
+}
+
+// RoundTrip logs the request, forwards it to the underlying transport and
+// logs the response.
+func (rt *bodyLoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	logger := rt.Logger()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return rt.Transport.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "instruqt request",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Any("headers", redactHeaders(req.Header)),
+		slog.String("body", redactBody(reqBody)),
+	)
+
+	start := time.Now()
+	resp, err := rt.Transport.RoundTrip(req)
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelDebug, "instruqt response",
+			slog.String("url", req.URL.String()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("error", err.Error()),
+		)
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	logger.LogAttrs(ctx, slog.LevelDebug, "instruqt response",
+		slog.String("url", req.URL.String()),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("body", redactBody(respBody)),
+	)
+
+	return resp, nil
+}
+
+// redactHeaders returns a copy of the headers with credentials redacted.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, key := range []string{"Authorization", "Cookie", "Set-Cookie"} {
+		if out.Get(key) != "" {
+			out.Set(key, redacted)
+		}
+	}
+	return out
+}
+
+// redactBody returns a JSON body with personal information redacted. Bodies
+// that are not JSON are replaced with a placeholder.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Sprintf("[%d bytes of non-JSON body]", len(body))
+	}
+
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes]", len(body))
+	}
+	return string(b)
+}
+
+// redactValue recursively redacts the PII fields of a decoded JSON value.
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if IsPIIField(key) {
+				if value != nil {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redactValue(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
+
+// discardLogger drops every record, so that the client does not log unless
+// asked to.
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler that drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
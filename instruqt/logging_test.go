@@ -0,0 +1,155 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestLogger returns a JSON logger writing debug records to buf.
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// decodeLogRecords decodes the JSON log records written to buf.
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger_Defaults(t *testing.T) {
+	client := NewClient("test-token", "isovalent")
+
+	assert.Nil(t, client.Logger)
+	assert.NotNil(t, client.InfoLogger)
+	assert.NotNil(t, client.DebugLogger)
+	assert.False(t, client.logger().Enabled(context.Background(), slog.LevelError), "Expected the client not to log by default")
+
+	// A deprecated InfoLogger set by the caller is still honored.
+	var buf bytes.Buffer
+	client.InfoLogger = log.New(&buf, "INFO: ", 0)
+	client.logger().Warn("instruqt operation failed")
+	assert.Contains(t, buf.String(), "instruqt operation failed")
+
+	// Logger takes precedence over InfoLogger.
+	logger := newTestLogger(&buf)
+	client.Logger = logger
+	assert.Same(t, logger, client.logger())
+}
+
+func TestLogOperation_Success(t *testing.T) {
+	var buf bytes.Buffer
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		Logger:        newTestLogger(&buf),
+		TeamSlug:      "isovalent",
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}}
+	}).Return(nil)
+
+	_, err := client.GetTracks()
+	assert.NoError(t, err)
+
+	records := decodeLogRecords(t, &buf)
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "GetTracks", record["operation"])
+	assert.Equal(t, "isovalent", record["team_slug"])
+	assert.Equal(t, float64(2), record["result_size"])
+	assert.Contains(t, record, "duration")
+	assert.Equal(t, map[string]any{"organizationSlug": "isovalent"}, record["ids"])
+}
+
+func TestLogOperation_Failure(t *testing.T) {
+	var buf bytes.Buffer
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		Logger:        newTestLogger(&buf),
+	}
+
+	mockClient.On("Mutate", mock.Anything, &stopSandboxMutation{}, mock.Anything).Return(errors.New("connection refused"))
+
+	err := client.StopSandbox("sandbox-123")
+	assert.Error(t, err)
+
+	records := decodeLogRecords(t, &buf)
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "StopSandbox", record["operation"])
+	assert.Equal(t, "transport", record["error_class"])
+	assert.Equal(t, map[string]any{"sandboxID": "sandbox-123"}, record["ids"])
+}
+
+func TestBodyLogging_RedactsTokenAndPII(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"user":{"id":"user-1","details":{"firstName":"Ada","lastName":"Lovelace","email":"ada@example.com"},"profile":null}}}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClientWithOptions("secret-token", "isovalent",
+		WithBaseURL(server.URL),
+		WithLogger(newTestLogger(&buf)),
+		WithBodyLogging(),
+	)
+
+	info, err := client.GetUserInfo("user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", info.Email)
+
+	logs := buf.String()
+	assert.Contains(t, logs, "instruqt request")
+	assert.Contains(t, logs, "instruqt response")
+	assert.Contains(t, logs, redacted)
+	assert.NotContains(t, logs, "secret-token")
+	assert.NotContains(t, logs, "ada@example.com")
+	assert.NotContains(t, logs, "Lovelace")
+}
+
+func TestRedactBody(t *testing.T) {
+	body := `{"variables":{"trackID":"track-1","userDetails":{"firstName":"Ada","email":"ada@example.com"}}}`
+
+	assert.Equal(t, `{"variables":{"trackID":"track-1","userDetails":"[REDACTED]"}}`, redactBody([]byte(body)))
+	assert.Equal(t, "[9 bytes of non-JSON body]", redactBody([]byte("not json!")))
+}
@@ -33,6 +33,11 @@ type options struct {
 	includeChallenges bool
 	includeReviews    bool
 
+	// Options for methods fanning out to several queries
+	concurrency int
+	errorPolicy ErrorPolicy
+	batchSize   int
+
 	// Options for GetInvite*
 	includeTracks bool
 
@@ -50,6 +55,9 @@ type options struct {
 	customParameterFilters []CustomParameterFilter
 	ordering               *Ordering
 
+	// Options for AllPlays
+	pageSize int
+
 	// Options for GetSandboxes
 	states  []SandboxState
 	poolIDs []string
@@ -97,6 +105,35 @@ func WithReviews() Option {
 	}
 }
 
+// WithConcurrency limits the number of queries run in parallel by methods
+// fanning out to several queries, such as GetTracks with WithChallenges.
+// Usage: GetTracks(WithChallenges(), WithConcurrency(4))
+func WithConcurrency(n int) Option {
+	return func(opts *options) {
+		opts.concurrency = n
+	}
+}
+
+// WithErrorPolicy sets how methods fanning out to several queries handle
+// failed queries.
+// Usage: GetTracks(WithChallenges(), WithErrorPolicy(ErrorPolicyCollectAll))
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(opts *options) {
+		opts.errorPolicy = policy
+	}
+}
+
+// WithBatchSize sets the number of lookups aliased into a single GraphQL
+// query by the Batch methods. Passed to GetTracks or GetUserTrackById along
+// with WithChallenges, it makes them fetch challenges in such batches instead
+// of one query per track or challenge.
+// Usage: GetTracks(WithChallenges(), WithBatchSize(25))
+func WithBatchSize(n int) Option {
+	return func(opts *options) {
+		opts.batchSize = n
+	}
+}
+
 // WithTracks is a functional option to include tracks.
 // Example usage: GetInvite("inviteID", WithTracks())
 func WithTracks() Option {
@@ -145,6 +182,15 @@ func WithPlayType(pt PlayType) Option {
 	}
 }
 
+// WithPageSize sets the number of items fetched per request by iterators
+// that page through results.
+// Usage: AllPlays(from, to, WithPageSize(50))
+func WithPageSize(size int) Option {
+	return func(opts *options) {
+		opts.pageSize = size
+	}
+}
+
 // WithStates sets the State filter for methods that support it.
 // Usage: GetSandboxes(WithStates(SandboxStateActive, SandboxStateCreating))
 func WithStates(states ...SandboxState) Option {
@@ -15,7 +15,9 @@
 package instruqt
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -103,6 +105,11 @@ type playItemQuery struct {
 //   - int: The total number of play reports available for the given criteria.
 //   - error: Any error encountered while retrieving the play reports.
 func (c *Client) GetPlays(from time.Time, to time.Time, take int, skip int, opts ...Option) ([]PlayReport, int, error) {
+	return c.GetPlaysCtx(c.context(), from, to, take, skip, opts...)
+}
+
+// GetPlaysCtx is like GetPlays but uses the given context instead of the client's Context.
+func (c *Client) GetPlaysCtx(ctx context.Context, from time.Time, to time.Time, take int, skip int, opts ...Option) ([]PlayReport, int, error) {
 	// Initialize the filter with default values
 	filters := &options{
 		trackIDs:               []string{},
@@ -176,14 +183,81 @@ func (c *Client) GetPlays(from time.Time, to time.Time, take int, skip int, opts
 	}
 
 	var q playQuery
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return nil, 0, fmt.Errorf("GraphQL query failed: %w", err)
+	if err := c.query(ctx, "GetPlays", &q, variables); err != nil {
+		return nil, 0, err
 	}
 
 	return q.PlayReports.Items, q.PlayReports.TotalItems, nil
 }
 
+// defaultPlaysPageSize is the number of play reports fetched per request by
+// AllPlays, unless overridden with WithPageSize.
+const defaultPlaysPageSize = 100
+
+// AllPlays returns an iterator over all play reports from Instruqt for the
+// specified team within a given date range. It pages through the results
+// transparently, fetching the next page only once the previous one has been
+// consumed, and stops as soon as the caller breaks out of the loop.
+//
+// If a request fails, the iterator yields the error once and stops.
+//
+// Parameters:
+//   - from: The start date of the date range filter.
+//   - to: The end date of the date range filter.
+//   - opts: A variadic number of Option to configure the query, such as
+//     WithPageSize or the filters supported by GetPlays.
+//
+// Returns:
+//   - iter.Seq2[PlayReport, error]: An iterator over the matching play reports.
+func (c *Client) AllPlays(from time.Time, to time.Time, opts ...Option) iter.Seq2[PlayReport, error] {
+	return c.AllPlaysCtx(c.context(), from, to, opts...)
+}
+
+// AllPlaysCtx is like AllPlays but uses the given context instead of the client's Context.
+func (c *Client) AllPlaysCtx(ctx context.Context, from time.Time, to time.Time, opts ...Option) iter.Seq2[PlayReport, error] {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	pageSize := options.pageSize
+	if pageSize <= 0 {
+		pageSize = defaultPlaysPageSize
+	}
+
+	return func(yield func(PlayReport, error) bool) {
+		skip := 0
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(PlayReport{}, err)
+				return
+			}
+
+			plays, total, err := c.GetPlaysCtx(ctx, from, to, pageSize, skip, opts...)
+			if err != nil {
+				yield(PlayReport{}, err)
+				return
+			}
+
+			for _, play := range plays {
+				if !yield(play, nil) {
+					return
+				}
+			}
+
+			skip += len(plays)
+			if len(plays) == 0 || skip >= total {
+				return
+			}
+		}
+	}
+}
+
 func (c *Client) GetPlayReportItem(playId string, opts ...Option) (*PlayReport, error) {
+	return c.GetPlayReportItemCtx(c.context(), playId, opts...)
+}
+
+// GetPlayReportItemCtx is like GetPlayReportItem but uses the given context instead of the client's Context.
+func (c *Client) GetPlayReportItemCtx(ctx context.Context, playId string, opts ...Option) (*PlayReport, error) {
 	// Initialize the filter with default values
 	filters := &options{
 		playType: PlayTypeAll, // Default PlayType
@@ -202,14 +276,14 @@ func (c *Client) GetPlayReportItem(playId string, opts ...Option) (*PlayReport,
 	}
 
 	var q playItemQuery
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return nil, fmt.Errorf("GraphQL query failed: %w", err)
+	if err := c.query(ctx, "GetPlayReportItem", &q, variables); err != nil {
+		return nil, err
 	}
 
 	if filters.includeChallenges {
-		challenges, err := c.GetChallenges(q.PlayReportItem.Track.Id)
+		challenges, err := c.GetChallengesCtx(ctx, q.PlayReportItem.Track.Id)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch challenges for track: %v", err)
+			return nil, fmt.Errorf("failed to fetch challenges for track: %w", err)
 		}
 		q.PlayReportItem.Track.Challenges = challenges
 	}
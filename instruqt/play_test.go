@@ -154,3 +154,91 @@ func TestGetPlays_Error(t *testing.T) {
 	// Ensure the mock expectations are met
 	mockClient.AssertExpectations(t)
 }
+
+// mockPlayPages sets up mockClient to serve the given play reports in pages,
+// honouring the take and skip variables of each query.
+func mockPlayPages(mockClient *MockGraphQLClient, plays []PlayReport) {
+	mockClient.On("Query", mock.Anything, &playQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		vars := args.Get(2).(map[string]interface{})
+		take, skip := int(vars["take"].(graphql.Int)), int(vars["skip"].(graphql.Int))
+
+		q := args.Get(1).(*playQuery)
+		q.PlayReports.TotalItems = len(plays)
+		q.PlayReports.Items = plays[min(skip, len(plays)):min(skip+take, len(plays))]
+	}).Return(nil)
+}
+
+// TestAllPlays_Paginates tests that AllPlays fetches every page of play reports.
+func TestAllPlays_Paginates(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockPlayPages(mockClient, []PlayReport{{Id: "play-1"}, {Id: "play-2"}, {Id: "play-3"}, {Id: "play-4"}, {Id: "play-5"}})
+
+	var ids []string
+	for play, err := range client.AllPlays(time.Time{}, time.Now(), WithPageSize(2)) {
+		assert.NoError(t, err)
+		ids = append(ids, play.Id)
+	}
+
+	assert.Equal(t, []string{"play-1", "play-2", "play-3", "play-4", "play-5"}, ids)
+	mockClient.AssertNumberOfCalls(t, "Query", 3)
+}
+
+// TestAllPlays_StopsOnBreak tests that AllPlays does not fetch further pages
+// once the caller stops iterating.
+func TestAllPlays_StopsOnBreak(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockPlayPages(mockClient, []PlayReport{{Id: "play-1"}, {Id: "play-2"}, {Id: "play-3"}, {Id: "play-4"}})
+
+	for play, err := range client.AllPlays(time.Time{}, time.Now(), WithPageSize(2)) {
+		assert.NoError(t, err)
+		if play.Id == "play-2" {
+			break
+		}
+	}
+
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+// TestAllPlays_Error tests that AllPlays yields a failed request as an error.
+func TestAllPlays_Error(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("graphql error")).Once()
+
+	var errs []error
+	for _, err := range client.AllPlays(time.Time{}, time.Now()) {
+		errs = append(errs, err)
+	}
+
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "graphql error")
+	mockClient.AssertExpectations(t)
+}
+
+// TestAllPlaysCtx_Canceled tests that AllPlaysCtx stops when its context is canceled.
+func TestAllPlaysCtx_Canceled(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var errs []error
+	for _, err := range client.AllPlaysCtx(ctx, time.Time{}, time.Now()) {
+		errs = append(errs, err)
+	}
+
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+	mockClient.AssertNotCalled(t, "Query", mock.Anything, mock.Anything, mock.Anything)
+}
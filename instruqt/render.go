@@ -0,0 +1,129 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RenderedAssignment is an assignment whose variable placeholders were
+// substituted with the variables of a sandbox.
+type RenderedAssignment struct {
+	Markdown string                        // The assignment, with the placeholders of the variables found substituted.
+	Values   map[SandboxVariableRef]string // The values of the variables found.
+	Missing  []AssignmentVariable          // The placeholders whose variable is not set, left as is, in order.
+}
+
+// Err returns a *MissingVariablesError listing the placeholders whose
+// variable is not set, or nil if every placeholder was substituted.
+func (r *RenderedAssignment) Err() error {
+	if len(r.Missing) == 0 {
+		return nil
+	}
+	return &MissingVariablesError{Variables: r.Missing}
+}
+
+// MissingVariablesError reports the placeholders of an assignment whose
+// variable is not set in the sandbox it was rendered against.
+type MissingVariablesError struct {
+	Variables []AssignmentVariable // The placeholders whose variable is not set, in order.
+}
+
+// Error implements the error interface, listing each missing variable as
+// hostname/key with the lines it is used on.
+func (e *MissingVariablesError) Error() string {
+	var refs []SandboxVariableRef
+	lines := make(map[SandboxVariableRef][]string)
+	for _, v := range e.Variables {
+		ref := SandboxVariableRef{Hostname: v.Hostname, Key: v.Key}
+		if _, ok := lines[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		lines[ref] = append(lines[ref], fmt.Sprint(v.Line))
+	}
+
+	missing := make([]string, len(refs))
+	for i, ref := range refs {
+		missing[i] = fmt.Sprintf("%s/%s (line %s)", ref.Hostname, ref.Key, strings.Join(lines[ref], ", "))
+	}
+	return "missing sandbox variables: " + strings.Join(missing, "; ")
+}
+
+// RenderAssignment substitutes the [[ Instruqt-Var ... ]] placeholders of an
+// assignment with the variables of a sandbox, fetched with batched
+// GetSandboxVariable queries. Unlike WithParsedAssignmentVariables, which
+// has the placeholders resolved by Instruqt, it works with any sandbox,
+// which makes it suitable to preview and check assignments. Variables that
+// are not set are reported in Missing.
+//
+// Parameters:
+//   - playID: The unique identifier of the sandbox environment.
+//   - assignment: The markdown of the assignment.
+//   - opts: Optional settings, such as WithBatchSize or WithConcurrency.
+//
+// Returns:
+//   - *RenderedAssignment: The rendered assignment.
+//   - error: Any error encountered while retrieving the variables.
+func (c *Client) RenderAssignment(playID string, assignment string, opts ...Option) (*RenderedAssignment, error) {
+	return c.RenderAssignmentCtx(c.context(), playID, assignment, opts...)
+}
+
+// RenderAssignmentCtx is like RenderAssignment but uses the given context instead of the client's Context.
+func (c *Client) RenderAssignmentCtx(ctx context.Context, playID string, assignment string, opts ...Option) (rendered *RenderedAssignment, err error) {
+	ctx, end := c.startOperation(ctx, "RenderAssignment", attribute.String("instruqt.playId", playID))
+	defer func() { end(err) }()
+
+	placeholders := ParseAssignment(assignment).Variables
+
+	// Fetch each variable once, however often it is used.
+	var refs []SandboxVariableRef
+	seen := make(map[SandboxVariableRef]bool)
+	for _, v := range placeholders {
+		ref := SandboxVariableRef{Hostname: v.Hostname, Key: v.Key}
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	values, err := c.BatchGetSandboxVariablesCtx(ctx, playID, refs, append([]Option{WithErrorPolicy(ErrorPolicyFailFast)}, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandbox variables: %w", err)
+	}
+
+	rendered = &RenderedAssignment{Values: make(map[SandboxVariableRef]string)}
+	for i, ref := range refs {
+		if values[i] != "" {
+			rendered.Values[ref] = values[i]
+		}
+	}
+
+	var replacements []string
+	for _, v := range placeholders {
+		value, ok := rendered.Values[SandboxVariableRef{Hostname: v.Hostname, Key: v.Key}]
+		if !ok {
+			rendered.Missing = append(rendered.Missing, v)
+			continue
+		}
+		replacements = append(replacements, v.Raw, value)
+	}
+	rendered.Markdown = strings.NewReplacer(replacements...).Replace(assignment)
+
+	return rendered, nil
+}
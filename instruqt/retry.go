@@ -0,0 +1,276 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+)
+
+// RetryPolicy configures how a RetryClient retries failed GraphQL calls.
+type RetryPolicy struct {
+	MaxRetries     int              // Maximum number of retries after the first attempt.
+	InitialBackoff time.Duration    // Backoff before the first retry, doubled on each subsequent retry.
+	MaxBackoff     time.Duration    // Upper bound for a single backoff. Longer Retry-After delays are not waited for.
+	MaxElapsedTime time.Duration    // Upper bound for the total time spent on a call, zero means no limit.
+	RetryMutations bool             // Whether mutations are retried. Queries are always retried.
+	IsRetryable    func(error) bool // Classifies errors as retryable, defaults to IsRetryableError.
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most callers. It
+// retries queries up to three times and never retries mutations.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		MaxElapsedTime: 2 * time.Minute,
+	}
+}
+
+// WithRetryPolicy wraps the client's GraphQL client in a RetryClient using
+// the given policy.
+// Usage: NewClientWithOptions(token, teamSlug, WithRetryPolicy(DefaultRetryPolicy()))
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+// RetryClient is a GraphQLClient that retries transient failures of an
+// underlying GraphQLClient with jittered exponential backoff. When the API
+// answers with a Retry-After header, the client waits at least that long
+// before the next attempt.
+type RetryClient struct {
+	GraphQLClient GraphQLClient // The GraphQL client used to execute queries and mutations.
+	Policy        RetryPolicy   // The policy deciding when and how often to retry.
+
+	sleep func(context.Context, time.Duration) error // Waits between attempts, replaced in tests.
+}
+
+// NewRetryClient creates a RetryClient wrapping the given GraphQL client.
+func NewRetryClient(client GraphQLClient, policy RetryPolicy) *RetryClient {
+	return &RetryClient{
+		GraphQLClient: client,
+		Policy:        policy,
+	}
+}
+
+// Query executes a GraphQL query, retrying it according to the policy.
+func (r *RetryClient) Query(ctx context.Context, q any, variables map[string]any, opts ...graphql.Option) error {
+	return r.do(ctx, func(ctx context.Context) error {
+		return r.GraphQLClient.Query(ctx, q, variables, opts...)
+	}, true)
+}
+
+// Mutate executes a GraphQL mutation. Mutations are only retried when the
+// policy has RetryMutations set.
+func (r *RetryClient) Mutate(ctx context.Context, m any, variables map[string]any, opts ...graphql.Option) error {
+	return r.do(ctx, func(ctx context.Context) error {
+		return r.GraphQLClient.Mutate(ctx, m, variables, opts...)
+	}, r.Policy.RetryMutations)
+}
+
+// do runs call until it succeeds, fails with a non-retryable error, or the
+// retry budget of the policy is exhausted.
+func (r *RetryClient) do(ctx context.Context, call func(context.Context) error, retry bool) error {
+	if !retry {
+		return call(ctx)
+	}
+
+	isRetryable := r.Policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableError
+	}
+	sleep := r.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		hint := &retryAfterHint{}
+		err := call(context.WithValue(ctx, retryAfterKey{}, hint))
+		if err == nil || attempt >= r.Policy.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		if retryAfter := hint.get(); retryAfter > delay {
+			// Retrying before the API asks us to would only be throttled again.
+			if r.Policy.MaxBackoff > 0 && retryAfter > r.Policy.MaxBackoff {
+				return err
+			}
+			delay = retryAfter
+		}
+		if r.Policy.MaxElapsedTime > 0 && time.Since(start)+delay > r.Policy.MaxElapsedTime {
+			return err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns the jittered delay before the given retry attempt. The
+// delay is drawn uniformly from the upper half of the exponential backoff.
+func (r *RetryClient) backoff(attempt int) time.Duration {
+	d := r.Policy.InitialBackoff
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if r.Policy.MaxBackoff > 0 && d >= r.Policy.MaxBackoff {
+			d = r.Policy.MaxBackoff
+			break
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// IsRetryableError reports whether err is a transient failure worth
+// retrying: rate limiting (429), server-side unavailability (500, 502, 503,
+// 504) and network-level errors such as timeouts or reset connections.
+// Context cancellation and GraphQL errors returned by the API are never
+// considered retryable.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr graphql.NetworkError
+	if errors.As(err, &netErr) {
+		switch netErr.StatusCode() {
+		case http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var timeoutErr interface{ Timeout() bool }
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfterKey is the context key under which a RetryClient stores the
+// retryAfterHint for the current attempt.
+type retryAfterKey struct{}
+
+// retryAfterHint carries the delay requested by the API through a
+// Retry-After header back from the transport to the RetryClient.
+type retryAfterHint struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (h *retryAfterHint) set(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.delay = d
+}
+
+func (h *retryAfterHint) get() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay
+}
+
+// retryAfterRoundTripper is an HTTP RoundTripper that reports the
+// Retry-After header of throttled responses to the RetryClient issuing the
+// request.
+type retryAfterRoundTripper struct {
+	Transport http.RoundTripper // The underlying transport to use for HTTP requests.
+}
+
+// RoundTrip forwards the request and records the Retry-After delay of 429
+// and 503 responses.
+func (rt *retryAfterRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.Transport.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if hint, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHint); ok {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				hint.set(d)
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		d := date.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// timeoutError is a network error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryClient_RetriesQueryOnServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"team":{"tpgPublicKey":"public-key"}}}`))
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client := NewClientWithOptions("test-token", "test-team",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(policy),
+	)
+
+	key, err := client.GetTPGPublicKey()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "public-key", key)
+	assert.Equal(t, int32(3), calls.Load(), "Expected two retries before success")
+}
+
+func TestRetryClient_HonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"team":{"tpgPublicKey":"public-key"}}}`))
+	}))
+	defer server.Close()
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client := NewClientWithOptions("test-token", "test-team",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(policy),
+	)
+
+	var delays []time.Duration
+	client.GraphQLClient.(*RetryClient).sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	_, err := client.GetTPGPublicKey()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{7 * time.Second}, delays, "Expected the Retry-After delay to be used")
+}
+
+func TestRetryClient_GivesUpAfterMaxRetries(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	retryClient := NewRetryClient(mockClient, RetryPolicy{MaxRetries: 2})
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(timeoutError{})
+
+	err := retryClient.Query(context.Background(), &teamQuery{}, nil)
+
+	assert.ErrorIs(t, err, timeoutError{})
+	mockClient.AssertNumberOfCalls(t, "Query", 3)
+}
+
+func TestRetryClient_DoesNotRetryNonRetryableErrors(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	retryClient := NewRetryClient(mockClient, RetryPolicy{MaxRetries: 2})
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("graphql error"))
+
+	err := retryClient.Query(context.Background(), &teamQuery{}, nil)
+
+	assert.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestRetryClient_MutationsRequireOptIn(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	retryClient := NewRetryClient(mockClient, RetryPolicy{MaxRetries: 2})
+
+	mockClient.On("Mutate", mock.Anything, mock.Anything, mock.Anything).Return(timeoutError{})
+
+	err := retryClient.Mutate(context.Background(), &stopSandboxMutation{}, nil)
+	assert.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "Mutate", 1)
+
+	retryClient.Policy.RetryMutations = true
+	err = retryClient.Mutate(context.Background(), &stopSandboxMutation{}, nil)
+	assert.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "Mutate", 4)
+}
+
+func TestRetryClient_CustomClassification(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	retryErr := errors.New("please retry")
+	retryClient := NewRetryClient(mockClient, RetryPolicy{
+		MaxRetries: 1,
+		IsRetryable: func(err error) bool {
+			return errors.Is(err, retryErr)
+		},
+	})
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(retryErr).Once()
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := retryClient.Query(context.Background(), &teamQuery{}, nil)
+
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestRetryClient_StopsOnContextCancellation(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	retryClient := NewRetryClient(mockClient, RetryPolicy{MaxRetries: 5, InitialBackoff: time.Hour})
+
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Return(timeoutError{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := retryClient.Query(ctx, &teamQuery{}, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	mockClient.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, d)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
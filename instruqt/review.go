@@ -15,7 +15,7 @@
 package instruqt
 
 import (
-	"fmt"
+	"context"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -48,6 +48,11 @@ type Review struct {
 // - *Review: A pointer to the retrieved Review. Includes Play if specified.
 // - error: An error object if the query fails or the review is not found.
 func (c *Client) GetReview(id string, opts ...Option) (*Review, error) {
+	return c.GetReviewCtx(c.context(), id, opts...)
+}
+
+// GetReviewCtx is like GetReview but uses the given context instead of the client's Context.
+func (c *Client) GetReviewCtx(ctx context.Context, id string, opts ...Option) (*Review, error) {
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -66,8 +71,8 @@ func (c *Client) GetReview(id string, opts ...Option) (*Review, error) {
 		}
 
 		// Execute the query.
-		if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-			return nil, fmt.Errorf("GraphQL query with play failed: %w", err)
+		if err := c.query(ctx, "GetReview", &q, variables); err != nil {
+			return nil, err
 		}
 
 		// Return the fetched Review, which includes Play.
@@ -80,8 +85,8 @@ func (c *Client) GetReview(id string, opts ...Option) (*Review, error) {
 	}
 
 	// Execute the query.
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return nil, fmt.Errorf("GraphQL query failed: %w", err)
+	if err := c.query(ctx, "GetReview", &q, variables); err != nil {
+		return nil, err
 	}
 
 	// Construct the Review without Play.
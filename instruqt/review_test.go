@@ -138,7 +138,10 @@ func TestGetReview_QueryError(t *testing.T) {
 	// Assertions to ensure an error is returned and review is nil.
 	assert.Error(t, err)
 	assert.Nil(t, review)
-	assert.Contains(t, err.Error(), "GraphQL query failed: network error")
+	assert.Contains(t, err.Error(), "GetReview: network error")
+	var transportErr *TransportError
+	assert.ErrorAs(t, err, &transportErr)
+	assert.Equal(t, "GetReview", transportErr.Operation)
 
 	// Ensure the mock expectations were met.
 	mockClient.AssertExpectations(t)
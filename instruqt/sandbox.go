@@ -15,6 +15,7 @@
 package instruqt
 
 import (
+	"context"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -88,6 +89,11 @@ type Sandbox struct {
 //   - string: The value of the requested sandbox variable.
 //   - error: Any error encountered while retrieving the variable.
 func (c *Client) GetSandboxVariable(playID string, hostname string, key string) (v string, err error) {
+	return c.GetSandboxVariableCtx(c.context(), playID, hostname, key)
+}
+
+// GetSandboxVariableCtx is like GetSandboxVariable but uses the given context instead of the client's Context.
+func (c *Client) GetSandboxVariableCtx(ctx context.Context, playID string, hostname string, key string) (v string, err error) {
 	if playID == "" || key == "" {
 		return v, nil
 	}
@@ -99,7 +105,7 @@ func (c *Client) GetSandboxVariable(playID string, hostname string, key string)
 		"key":       graphql.String(key),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetSandboxVariable", &q, variables); err != nil {
 		return v, err
 	}
 
@@ -109,6 +115,11 @@ func (c *Client) GetSandboxVariable(playID string, hostname string, key string)
 // SetSandboxVariable sets a specific variable in a sandbox environment
 // using the sandbox ID, variable key, and value.
 func (c *Client) SetSandboxVariable(playID string, hostname string, key string, value string) error {
+	return c.SetSandboxVariableCtx(c.context(), playID, hostname, key, value)
+}
+
+// SetSandboxVariableCtx is like SetSandboxVariable but uses the given context instead of the client's Context.
+func (c *Client) SetSandboxVariableCtx(ctx context.Context, playID string, hostname string, key string, value string) error {
 	if playID == "" || key == "" || value == "" {
 		return nil
 	}
@@ -121,7 +132,7 @@ func (c *Client) SetSandboxVariable(playID string, hostname string, key string,
 		"value":     graphql.String(value),
 	}
 
-	if err := c.GraphQLClient.Mutate(c.Context, &q, variables); err != nil {
+	if err := c.mutate(ctx, "SetSandboxVariable", &q, variables); err != nil {
 		return err
 	}
 
@@ -134,6 +145,11 @@ func (c *Client) SetSandboxVariable(playID string, hostname string, key string,
 //   - Sandbox: The sandbox.
 //   - error: Any error encountered while retrieving the sandbox.
 func (c *Client) GetSandbox(id string, opts ...Option) (s Sandbox, err error) {
+	return c.GetSandboxCtx(c.context(), id, opts...)
+}
+
+// GetSandboxCtx is like GetSandbox but uses the given context instead of the client's Context.
+func (c *Client) GetSandboxCtx(ctx context.Context, id string, opts ...Option) (s Sandbox, err error) {
 	// Initialize the filter with default values
 	filters := &options{
 		playType: PlayTypeAll, // Default PlayType
@@ -150,7 +166,7 @@ func (c *Client) GetSandbox(id string, opts ...Option) (s Sandbox, err error) {
 		"teamSlug": graphql.String(c.TeamSlug), // Pass teamSlug for User info
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetSandbox", &q, variables); err != nil {
 		return s, err
 	}
 
@@ -163,6 +179,11 @@ func (c *Client) GetSandbox(id string, opts ...Option) (s Sandbox, err error) {
 //   - []Sandbox: A list of sandboxes for the team.
 //   - error: Any error encountered while retrieving the sandboxes.
 func (c *Client) GetSandboxes(opts ...Option) (s []Sandbox, err error) {
+	return c.GetSandboxesCtx(c.context(), opts...)
+}
+
+// GetSandboxesCtx is like GetSandboxes but uses the given context instead of the client's Context.
+func (c *Client) GetSandboxesCtx(ctx context.Context, opts ...Option) (s []Sandbox, err error) {
 	// Initialize the filter with default values
 	filters := &options{
 		playType: PlayTypeAll, // Default PlayType
@@ -204,7 +225,7 @@ func (c *Client) GetSandboxes(opts ...Option) (s []Sandbox, err error) {
 		"state":           filters.states,
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetSandboxes", &q, variables); err != nil {
 		return s, err
 	}
 
@@ -226,12 +247,17 @@ type stopSandboxMutation struct {
 // Returns:
 //   - error: Any error encountered while stopping the sandbox.
 func (c *Client) StopSandbox(sandboxID string) error {
+	return c.StopSandboxCtx(c.context(), sandboxID)
+}
+
+// StopSandboxCtx is like StopSandbox but uses the given context instead of the client's Context.
+func (c *Client) StopSandboxCtx(ctx context.Context, sandboxID string) error {
 	var m stopSandboxMutation
 	variables := map[string]interface{}{
 		"sandboxID": graphql.String(sandboxID),
 	}
 
-	if err := c.GraphQLClient.Mutate(c.Context, &m, variables); err != nil {
+	if err := c.mutate(ctx, "StopSandbox", &m, variables); err != nil {
 		return err
 	}
 
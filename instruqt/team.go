@@ -15,6 +15,7 @@
 package instruqt
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -41,13 +42,18 @@ type teamQuery struct {
 //   - string: The TPG public key of the team.
 //   - error: Any error encountered while retrieving the TPG public key.
 func (c *Client) GetTPGPublicKey() (string, error) {
+	return c.GetTPGPublicKeyCtx(c.context())
+}
+
+// GetTPGPublicKeyCtx is like GetTPGPublicKey but uses the given context instead of the client's Context.
+func (c *Client) GetTPGPublicKeyCtx(ctx context.Context) (string, error) {
 	var q teamQuery
 	variables := map[string]interface{}{
 		"teamSlug": graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return "", fmt.Errorf("failed to retrieve TPG Public Key: %v", err)
+	if err := c.query(ctx, "GetTPGPublicKey", &q, variables); err != nil {
+		return "", err
 	}
 
 	return string(q.Team.TPGPublicKey), nil
@@ -56,10 +62,15 @@ func (c *Client) GetTPGPublicKey() (string, error) {
 // EncryptPII encrypts PII using the public key fetched from the GetTPGPublicKey function.
 // It takes a string representing the PII data, encodes it, and then encrypts it using RSA.
 func (c *Client) EncryptPII(encodedPII string) (string, error) {
+	return c.EncryptPIICtx(c.context(), encodedPII)
+}
+
+// EncryptPIICtx is like EncryptPII but uses the given context instead of the client's Context.
+func (c *Client) EncryptPIICtx(ctx context.Context, encodedPII string) (string, error) {
 	// Fetch the public key using the GetTPGPublicKey function
-	publicKeyPEM, err := c.GetTPGPublicKey()
+	publicKeyPEM, err := c.GetTPGPublicKeyCtx(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get public key: %v", err)
+		return "", fmt.Errorf("failed to get public key: %w", err)
 	}
 
 	// Decode the PEM public key
@@ -71,7 +82,7 @@ func (c *Client) EncryptPII(encodedPII string) (string, error) {
 	// Parse the public key
 	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse DER encoded public key: %v", err)
+		return "", fmt.Errorf("failed to parse DER encoded public key: %w", err)
 	}
 
 	// Assert the public key is of type *rsa.PublicKey
@@ -84,7 +95,7 @@ func (c *Client) EncryptPII(encodedPII string) (string, error) {
 	hash := sha256.New()
 	encryptedPII, err := rsa.EncryptOAEP(hash, rand.Reader, rsaPublicKey, []byte(encodedPII), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to encrypt PII: %v", err)
+		return "", fmt.Errorf("failed to encrypt PII: %w", err)
 	}
 
 	// Encode the encrypted data to base64
@@ -94,6 +105,11 @@ func (c *Client) EncryptPII(encodedPII string) (string, error) {
 
 // EncryptUserPII creates PII data (first name, last name, and email) and encrypts it using the public key.
 func (c *Client) EncryptUserPII(firstName, lastName, email string) (string, error) {
+	return c.EncryptUserPIICtx(c.context(), firstName, lastName, email)
+}
+
+// EncryptUserPIICtx is like EncryptUserPII but uses the given context instead of the client's Context.
+func (c *Client) EncryptUserPIICtx(ctx context.Context, firstName, lastName, email string) (string, error) {
 	// Prepare the PII data
 	piiData := url.Values{
 		"fn": {firstName},
@@ -102,7 +118,7 @@ func (c *Client) EncryptUserPII(firstName, lastName, email string) (string, erro
 	}
 
 	// Encrypt the PII data
-	encryptedPII, err := c.EncryptPII(piiData.Encode())
+	encryptedPII, err := c.EncryptPIICtx(ctx, piiData.Encode())
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,179 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer and meter of the client.
+const instrumentationName = "github.com/isovalent/instruqt-go/instruqt"
+
+// Attribute keys set on the spans and metrics of the client.
+const (
+	attrOperation     = attribute.Key("instruqt.operation")
+	attrTeamSlug      = attribute.Key("instruqt.team_slug")
+	attrErrorClass    = attribute.Key("instruqt.error_class")
+	attrOperationType = attribute.Key("graphql.operation.type")
+	attrOperationName = attribute.Key("graphql.operation.name")
+)
+
+// WithTracerProvider enables tracing. Every GraphQL call gets a client span
+// carrying the operation name, team slug and entity IDs, and methods that
+// fan out to several calls, such as GetUserTrackById, get a parent span
+// covering all of them.
+// Usage: NewClientWithOptions(token, teamSlug, WithTracerProvider(otel.GetTracerProvider()))
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(o *clientOptions) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider enables metrics. The client records the number of
+// GraphQL calls, the number of failed calls and their latency, by operation.
+// Usage: NewClientWithOptions(token, teamSlug, WithMeterProvider(otel.GetMeterProvider()))
+func WithMeterProvider(mp metric.MeterProvider) ClientOption {
+	return func(o *clientOptions) {
+		o.meterProvider = mp
+	}
+}
+
+// clientMetrics holds the instruments recording GraphQL calls.
+type clientMetrics struct {
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// newClientMetrics creates the instruments of the client from mp.
+func newClientMetrics(mp metric.MeterProvider) *clientMetrics {
+	meter := mp.Meter(instrumentationName)
+	m := &clientMetrics{}
+
+	var err error
+	m.requests, err = meter.Int64Counter("instruqt.client.requests",
+		metric.WithDescription("Number of GraphQL calls made to the Instruqt API."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	m.errors, err = meter.Int64Counter("instruqt.client.errors",
+		metric.WithDescription("Number of GraphQL calls to the Instruqt API that failed."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	m.duration, err = meter.Float64Histogram("instruqt.client.duration",
+		metric.WithDescription("Duration of GraphQL calls made to the Instruqt API."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return m
+}
+
+// startCall starts the span of a GraphQL call. The returned function ends
+// the span and records the metrics of the call. Without a tracer, ctx is
+// returned unchanged.
+func (c *Client) startCall(ctx context.Context, operationType string, operation string, variables map[string]any) (context.Context, func(error)) {
+	if c.tracer == nil && c.metrics == nil {
+		return ctx, func(error) {}
+	}
+
+	var span trace.Span
+	if c.tracer != nil {
+		attrs := []attribute.KeyValue{
+			attrOperation.String(operation),
+			attrTeamSlug.String(c.TeamSlug),
+			attrOperationType.String(operationType),
+			attrOperationName.String(operation),
+		}
+		attrs = append(attrs, identifierAttributes(variables)...)
+		ctx, span = c.tracer.Start(ctx, operationType+" "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+	}
+
+	start := time.Now()
+	return ctx, func(err error) {
+		if c.metrics != nil {
+			attrs := []attribute.KeyValue{
+				attrOperation.String(operation),
+				attrOperationType.String(operationType),
+				attrTeamSlug.String(c.TeamSlug),
+			}
+			c.metrics.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+			c.metrics.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+			if err != nil {
+				attrs = append(attrs, attrErrorClass.String(errorClass(err)))
+				c.metrics.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+		}
+		if span != nil {
+			endSpan(span, err)
+		}
+	}
+}
+
+// startOperation starts the span of a client method fanning out to several
+// GraphQL calls, which become its children. The returned function ends the
+// span. Without a tracer, ctx is returned unchanged.
+func (c *Client) startOperation(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	if c.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	attrs = append([]attribute.KeyValue{
+		attrOperation.String(operation),
+		attrTeamSlug.String(c.TeamSlug),
+	}, attrs...)
+	ctx, span := c.tracer.Start(ctx, operation, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		endSpan(span, err)
+	}
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attrErrorClass.String(errorClass(err)))
+	}
+	span.End()
+}
+
+// identifierAttributes returns the ID and slug variables of a GraphQL call
+// as span attributes, such as instruqt.trackId.
+func identifierAttributes(variables map[string]any) []attribute.KeyValue {
+	keys := identifierKeys(variables)
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, attribute.String("instruqt."+key, fmt.Sprint(variables[key])))
+	}
+	return attrs
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newInstrumentedClient returns a client backed by mockClient that records
+// its spans and metrics in memory.
+func newInstrumentedClient(mockClient *MockGraphQLClient) (*Client, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	client := NewClientWithOptions("test-token", "isovalent",
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	client.GraphQLClient = mockClient
+	return client, recorder, reader
+}
+
+// spanAttributes returns the attributes of span as a map.
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+// findMetric returns the metric with the given name collected by reader.
+func findMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %q not found", name)
+	return metricdata.Metrics{}
+}
+
+func TestTelemetry_QuerySpanAndMetrics(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client, recorder, reader := newInstrumentedClient(mockClient)
+
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		assert.True(t, trace.SpanContextFromContext(args.Get(0).(context.Context)).IsValid(), "Expected the span to be propagated")
+	}).Return(nil)
+
+	_, err := client.GetTrackById("track-123")
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	span, parent := spans[0], spans[1]
+	assert.Equal(t, "GetTrackById", parent.Name())
+	assert.Equal(t, "track-123", spanAttributes(parent)["instruqt.trackId"])
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "Expected the query span to be a child span")
+	assert.Equal(t, "query GetTrackById", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	attrs := spanAttributes(span)
+	assert.Equal(t, "GetTrackById", attrs["instruqt.operation"])
+	assert.Equal(t, "isovalent", attrs["instruqt.team_slug"])
+	assert.Equal(t, "track-123", attrs["instruqt.trackId"])
+
+	requests := findMetric(t, reader, "instruqt.client.requests").Data.(metricdata.Sum[int64])
+	assert.Len(t, requests.DataPoints, 1)
+	assert.Equal(t, int64(1), requests.DataPoints[0].Value)
+	op, _ := requests.DataPoints[0].Attributes.Value(attrOperation)
+	assert.Equal(t, "GetTrackById", op.AsString())
+
+	duration := findMetric(t, reader, "instruqt.client.duration").Data.(metricdata.Histogram[float64])
+	assert.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+}
+
+func TestTelemetry_RecordsErrors(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client, recorder, reader := newInstrumentedClient(mockClient)
+
+	mockClient.On("Mutate", mock.Anything, &stopSandboxMutation{}, mock.Anything).Return(errors.New("connection refused"))
+
+	err := client.StopSandbox("sandbox-123")
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "mutation StopSandbox", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "transport", spanAttributes(spans[0])["instruqt.error_class"])
+
+	errs := findMetric(t, reader, "instruqt.client.errors").Data.(metricdata.Sum[int64])
+	assert.Len(t, errs.DataPoints, 1)
+	assert.Equal(t, int64(1), errs.DataPoints[0].Value)
+	class, _ := errs.DataPoints[0].Attributes.Value(attrErrorClass)
+	assert.Equal(t, "transport", class.AsString())
+}
+
+func TestTelemetry_CompositeOperationSpan(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client, recorder, _ := newInstrumentedClient(mockClient)
+
+	mockClient.On("Query", mock.Anything, &sandboxTrackQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{{Id: "challenge-1"}, {Id: "challenge-2"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &userChallengeQuery{}, mock.Anything).Return(nil)
+
+	_, err := client.GetUserTrackById("user-123", "track-123", WithChallenges())
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 5)
+
+	parent := spans[len(spans)-1]
+	assert.Equal(t, "GetUserTrackById", parent.Name())
+	assert.Equal(t, "user-123", spanAttributes(parent)["instruqt.userId"])
+	assert.Equal(t, "track-123", spanAttributes(parent)["instruqt.trackId"])
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "Expected %s to be a child span", span.Name())
+	}
+}
+
+func TestTelemetry_DisabledByDefault(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{GraphQLClient: mockClient}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient.On("Query", ctx, &trackQuery{}, mock.Anything).Return(nil)
+
+	_, err := client.GetTrackByIdCtx(ctx, "track-123")
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
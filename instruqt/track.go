@@ -15,10 +15,13 @@
 package instruqt
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // trackQuery represents the GraphQL query structure for retrieving a single
@@ -125,10 +128,18 @@ type SandboxTrack struct {
 //   - Track: The track details if found.
 //   - error: Any error encountered while retrieving the track.
 func (c *Client) GetTrackById(trackId string, opts ...Option) (t Track, err error) {
+	return c.GetTrackByIdCtx(c.context(), trackId, opts...)
+}
+
+// GetTrackByIdCtx is like GetTrackById but uses the given context instead of the client's Context.
+func (c *Client) GetTrackByIdCtx(ctx context.Context, trackId string, opts ...Option) (t Track, err error) {
 	if trackId == "" {
 		return t, nil
 	}
 
+	ctx, end := c.startOperation(ctx, "GetTrackById", attribute.String("instruqt.trackId", trackId))
+	defer func() { end(err) }()
+
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -140,22 +151,22 @@ func (c *Client) GetTrackById(trackId string, opts ...Option) (t Track, err erro
 		"trackId": graphql.String(trackId),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetTrackById", &q, variables); err != nil {
 		return t, err
 	}
 
 	if options.includeChallenges {
-		challenges, err := c.GetChallenges(trackId)
+		challenges, err := c.GetChallengesCtx(ctx, trackId)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch challenges for track: %v", err)
+			return t, fmt.Errorf("failed to fetch challenges for track: %w", err)
 		}
 		q.Track.Challenges = challenges
 	}
 
 	if options.includeReviews {
-		count, reviews, err := c.GetReviews(trackId, opts...)
+		count, reviews, err := c.GetReviewsCtx(ctx, trackId, opts...)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch reviews for track: %v", err)
+			return t, fmt.Errorf("failed to fetch reviews for track: %w", err)
 		}
 		q.Track.TrackReviews.TotalCount = count
 		q.Track.TrackReviews.Nodes = reviews
@@ -167,6 +178,11 @@ func (c *Client) GetTrackById(trackId string, opts ...Option) (t Track, err erro
 // GetUserTrackById retrieves a track for a specific user, including its challenges,
 // using the user's ID and the track's ID.
 //
+// With WithChallenges, the user's progress on each challenge is fetched in
+// parallel, up to the limit set by WithConcurrency. With
+// WithErrorPolicy(ErrorPolicyCollectAll), the track is returned along with
+// the challenges that could be fetched and the joined errors of the others.
+//
 // Parameters:
 // - userId: The unique identifier of the user.
 // - trackId: The unique identifier of the track.
@@ -176,10 +192,21 @@ func (c *Client) GetTrackById(trackId string, opts ...Option) (t Track, err erro
 //   - SandboxTrack: The track details with challenges if found.
 //   - error: Any error encountered while retrieving the track.
 func (c *Client) GetUserTrackById(userId string, trackId string, opts ...Option) (t SandboxTrack, err error) {
+	return c.GetUserTrackByIdCtx(c.context(), userId, trackId, opts...)
+}
+
+// GetUserTrackByIdCtx is like GetUserTrackById but uses the given context instead of the client's Context.
+func (c *Client) GetUserTrackByIdCtx(ctx context.Context, userId string, trackId string, opts ...Option) (t SandboxTrack, err error) {
 	if trackId == "" {
 		return t, nil
 	}
 
+	ctx, end := c.startOperation(ctx, "GetUserTrackById",
+		attribute.String("instruqt.userId", userId),
+		attribute.String("instruqt.trackId", trackId),
+	)
+	defer func() { end(err) }()
+
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -193,29 +220,56 @@ func (c *Client) GetUserTrackById(userId string, trackId string, opts ...Option)
 		"organizationSlug": graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetUserTrackById", &q, variables); err != nil {
 		return t, err
 	}
 
 	if options.includeChallenges {
-		challenges, err := c.GetChallenges(trackId)
+		challenges, err := c.GetChallengesCtx(ctx, trackId)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch challenges for track: %v", err)
+			return t, fmt.Errorf("failed to fetch challenges for track: %w", err)
 		}
-		for i, ch := range challenges {
-			if cch, err := c.GetUserChallenge(userId, ch.Id); err == nil {
-				challenges[i] = cch
-			} else {
-				return t, err
+		if options.batchSize > 0 {
+			ids := make([]string, len(challenges))
+			for i, ch := range challenges {
+				ids[i] = ch.Id
+			}
+			var (
+				userChallenges []Challenge
+				errs           []error
+			)
+			userChallenges, errs, err = c.batchGetUserChallenges(ctx, userId, ids, options)
+			if userChallenges != nil {
+				// Keep the challenges whose lookup failed, as fanOut does.
+				for i := range challenges {
+					if errs[i] == nil {
+						challenges[i] = userChallenges[i]
+					}
+				}
 			}
+		} else {
+			err = fanOut(ctx, len(challenges), options.concurrency, options.errorPolicy, func(ctx context.Context, i int) error {
+				ch, err := c.GetUserChallengeCtx(ctx, userId, challenges[i].Id)
+				if err != nil {
+					return err
+				}
+				challenges[i] = ch
+				return nil
+			})
+		}
+		if err != nil && options.errorPolicy == ErrorPolicyFailFast {
+			return t, err
 		}
 		q.Track.Challenges = challenges
+		if err != nil {
+			return q.Track, err
+		}
 	}
 
 	if options.includeReviews {
-		count, reviews, err := c.GetReviews(trackId, opts...)
+		count, reviews, err := c.GetReviewsCtx(ctx, trackId, opts...)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch reviews for track: %v", err)
+			return t, fmt.Errorf("failed to fetch reviews for track: %w", err)
 		}
 		q.Track.TrackReviews.TotalCount = count
 		q.Track.TrackReviews.Nodes = reviews
@@ -234,10 +288,18 @@ func (c *Client) GetUserTrackById(userId string, trackId string, opts ...Option)
 //   - Track: The track details if found.
 //   - error: Any error encountered while retrieving the track.
 func (c *Client) GetTrackBySlug(trackSlug string, opts ...Option) (t Track, err error) {
+	return c.GetTrackBySlugCtx(c.context(), trackSlug, opts...)
+}
+
+// GetTrackBySlugCtx is like GetTrackBySlug but uses the given context instead of the client's Context.
+func (c *Client) GetTrackBySlugCtx(ctx context.Context, trackSlug string, opts ...Option) (t Track, err error) {
 	if trackSlug == "" {
 		return t, nil
 	}
 
+	ctx, end := c.startOperation(ctx, "GetTrackBySlug", attribute.String("instruqt.trackSlug", trackSlug))
+	defer func() { end(err) }()
+
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -250,22 +312,22 @@ func (c *Client) GetTrackBySlug(trackSlug string, opts ...Option) (t Track, err
 		"teamSlug":  graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetTrackBySlug", &q, variables); err != nil {
 		return t, err
 	}
 
 	if options.includeChallenges {
-		challenges, err := c.GetChallenges(q.Track.Id)
+		challenges, err := c.GetChallengesCtx(ctx, q.Track.Id)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch challenges for track: %v", err)
+			return t, fmt.Errorf("failed to fetch challenges for track: %w", err)
 		}
 		q.Track.Challenges = challenges
 	}
 
 	if options.includeReviews {
-		count, reviews, err := c.GetReviews(q.Track.Id, opts...)
+		count, reviews, err := c.GetReviewsCtx(ctx, q.Track.Id, opts...)
 		if err != nil {
-			return t, fmt.Errorf("failed to fetch reviews for track: %v", err)
+			return t, fmt.Errorf("failed to fetch reviews for track: %w", err)
 		}
 		q.Track.TrackReviews.TotalCount = count
 		q.Track.TrackReviews.Nodes = reviews
@@ -285,9 +347,20 @@ func (c *Client) GetTrackBySlug(trackSlug string, opts ...Option) (t Track, err
 //   - Challenge: The first unlocked challenge found.
 //   - error: Any error encountered while retrieving the challenge.
 func (c *Client) GetTrackUnlockedChallenge(userId string, trackId string) (challenge Challenge, err error) {
-	track, err := c.GetUserTrackById(userId, trackId, WithChallenges())
+	return c.GetTrackUnlockedChallengeCtx(c.context(), userId, trackId)
+}
+
+// GetTrackUnlockedChallengeCtx is like GetTrackUnlockedChallenge but uses the given context instead of the client's Context.
+func (c *Client) GetTrackUnlockedChallengeCtx(ctx context.Context, userId string, trackId string) (challenge Challenge, err error) {
+	ctx, end := c.startOperation(ctx, "GetTrackUnlockedChallenge",
+		attribute.String("instruqt.userId", userId),
+		attribute.String("instruqt.trackId", trackId),
+	)
+	defer func() { end(err) }()
+
+	track, err := c.GetUserTrackByIdCtx(ctx, userId, trackId, WithChallenges())
 	if err != nil {
-		return challenge, fmt.Errorf("[instruqt.GetTrackUnlockedChallenge] failed to get user track: %v", err)
+		return challenge, fmt.Errorf("[instruqt.GetTrackUnlockedChallenge] failed to get user track: %w", err)
 	}
 
 	for _, chllg := range track.Challenges {
@@ -301,7 +374,12 @@ func (c *Client) GetTrackUnlockedChallenge(userId string, trackId string) (chall
 }
 
 // GetTracks retrieves all tracks associated with the client's team slug.
-
+//
+// With WithChallenges or WithReviews, the enrichments of the tracks are
+// fetched in parallel, up to the limit set by WithConcurrency. With
+// WithErrorPolicy(ErrorPolicyCollectAll), the tracks are returned along with
+// the enrichments that could be fetched and the joined errors of the others.
+//
 // Parameters:
 // - opts (...Option): Variadic functional options to modify the query behavior.
 //
@@ -309,6 +387,14 @@ func (c *Client) GetTrackUnlockedChallenge(userId string, trackId string) (chall
 // - []Track: A list of tracks for the team.
 // - error: Any error encountered while retrieving the tracks.
 func (c *Client) GetTracks(opts ...Option) (tt []Track, err error) {
+	return c.GetTracksCtx(c.context(), opts...)
+}
+
+// GetTracksCtx is like GetTracks but uses the given context instead of the client's Context.
+func (c *Client) GetTracksCtx(ctx context.Context, opts ...Option) (tt []Track, err error) {
+	ctx, end := c.startOperation(ctx, "GetTracks")
+	defer func() { end(err) }()
+
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -320,48 +406,179 @@ func (c *Client) GetTracks(opts ...Option) (tt []Track, err error) {
 		"organizationSlug": graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetTracks", &q, variables); err != nil {
 		return tt, err
 	}
 
-	if options.includeChallenges {
-		for _, t := range q.Tracks {
-			challenges, err := c.GetChallenges(t.Id)
-			if err != nil {
-				return tt, fmt.Errorf("failed to fetch challenges for track: %v", err)
+	if _, err = c.enrichTracks(ctx, q.Tracks, options, opts); err != nil && options.errorPolicy == ErrorPolicyFailFast {
+		return tt, err
+	}
+
+	return q.Tracks, err
+}
+
+// TrackEnrichment holds the tracks of a team along with the enrichments that
+// failed for each of them, so that one broken track does not hide the rest
+// of the catalog.
+type TrackEnrichment struct {
+	Tracks []Track          // The tracks of the team, with the enrichments that could be fetched.
+	Errors map[string]error // The errors encountered while enriching tracks, by track ID.
+}
+
+// Err returns the joined errors of all tracks, in track order, or nil if
+// every track was enriched.
+func (e *TrackEnrichment) Err() error {
+	var errs []error
+	for _, t := range e.Tracks {
+		errs = append(errs, e.Errors[t.Id])
+	}
+	return errors.Join(errs...)
+}
+
+// GetTracksEnrichment retrieves all tracks associated with the client's team
+// slug, along with the enrichments requested with WithChallenges or
+// WithReviews. Unlike GetTracks, a failed enrichment never fails the whole
+// list: it is reported in the Errors of the result for its track.
+//
+// Parameters:
+//   - opts: Optional settings, such as WithChallenges, WithReviews,
+//     WithConcurrency or WithBatchSize. WithErrorPolicy is ignored.
+//
+// Returns:
+//   - *TrackEnrichment: The tracks and the errors of their enrichments.
+//   - error: Any error encountered while retrieving the list of tracks.
+func (c *Client) GetTracksEnrichment(opts ...Option) (*TrackEnrichment, error) {
+	return c.GetTracksEnrichmentCtx(c.context(), opts...)
+}
+
+// GetTracksEnrichmentCtx is like GetTracksEnrichment but uses the given context instead of the client's Context.
+func (c *Client) GetTracksEnrichmentCtx(ctx context.Context, opts ...Option) (enrichment *TrackEnrichment, err error) {
+	ctx, end := c.startOperation(ctx, "GetTracksEnrichment")
+	defer func() { end(err) }()
+
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.errorPolicy = ErrorPolicyCollectAll
+
+	var q tracksQuery
+	variables := map[string]interface{}{
+		"organizationSlug": graphql.String(c.TeamSlug),
+	}
+
+	if err := c.query(ctx, "GetTracks", &q, variables); err != nil {
+		return nil, err
+	}
+
+	errs, err := c.enrichTracks(ctx, q.Tracks, options, opts)
+	if ctx.Err() != nil {
+		// Tracks that were not reached have neither enrichments nor errors.
+		return nil, err
+	}
+
+	enrichment = &TrackEnrichment{
+		Tracks: q.Tracks,
+		Errors: make(map[string]error),
+	}
+	for i, err := range errs {
+		if err != nil {
+			enrichment.Errors[q.Tracks[i].Id] = err
+		}
+	}
+	return enrichment, nil
+}
+
+// enrichTracks fetches the enrichments requested in options for each track,
+// in place. It returns the error of each track, in the same order as tracks,
+// along with the joined errors of all of them. With ErrorPolicyFailFast, the
+// first failure stops the enrichment and only the joined error is returned.
+func (c *Client) enrichTracks(ctx context.Context, tracks []Track, options *options, opts []Option) ([]error, error) {
+	errs := make([]error, len(tracks))
+
+	// With a batch size, the challenges of all tracks are fetched using
+	// aliased queries rather than one query per track.
+	includeChallenges := options.includeChallenges
+	var batchErr error
+	if includeChallenges && options.batchSize > 0 {
+		ids := make([]string, len(tracks))
+		for i, t := range tracks {
+			ids[i] = t.Id
+		}
+		shared := map[string]any{
+			"teamSlug": graphql.String(c.TeamSlug),
+		}
+		challenges, challengeErrs, err := batchQueryEach[[]Challenge](ctx, c, "BatchGetTrackChallenges", "challenges(trackID: %s, teamSlug: $teamSlug)", ids, graphqlString, shared, options)
+		if err != nil && options.errorPolicy == ErrorPolicyFailFast {
+			return nil, fmt.Errorf("failed to fetch challenges for tracks: %w", err)
+		}
+		for i := range challenges {
+			tracks[i].Challenges = challenges[i]
+			if challengeErrs[i] != nil {
+				errs[i] = fmt.Errorf("failed to fetch challenges for track %s: %w", tracks[i].Id, challengeErrs[i])
 			}
-			t.Challenges = challenges
 		}
+		includeChallenges = false
+		batchErr = err
 	}
 
-	if options.includeReviews {
-		for _, t := range q.Tracks {
-			count, reviews, err := c.GetReviews(t.Id, opts...)
+	if !includeChallenges && !options.includeReviews {
+		return errs, batchErr
+	}
+
+	// Index the tracks rather than ranging over them by value, so that the
+	// enrichments are stored in the returned tracks.
+	err := fanOut(ctx, len(tracks), options.concurrency, options.errorPolicy, func(ctx context.Context, i int) error {
+		t := &tracks[i]
+		if includeChallenges {
+			challenges, err := c.GetChallengesCtx(ctx, t.Id)
 			if err != nil {
-				return tt, fmt.Errorf("failed to fetch reviews for track: %v", err)
+				errs[i] = fmt.Errorf("failed to fetch challenges for track %s: %w", t.Id, err)
+				return errs[i]
+			}
+			t.Challenges = challenges
+		}
+		if options.includeReviews {
+			count, reviews, err := c.GetReviewsCtx(ctx, t.Id, opts...)
+			if err != nil {
+				err = fmt.Errorf("failed to fetch reviews for track %s: %w", t.Id, err)
+				errs[i] = errors.Join(errs[i], err)
+				return err
 			}
 			t.TrackReviews.TotalCount = count
 			t.TrackReviews.Nodes = reviews
 		}
+		return nil
+	})
+	if err != nil && options.errorPolicy == ErrorPolicyFailFast {
+		return nil, err
 	}
 
-	return q.Tracks, nil
+	return errs, errors.Join(batchErr, err)
 }
 
 // GetTracksInMaintenance returns the slugs of tracks currently placed in
 // maintenance mode. Its GraphQL query intentionally requests only slug and
 // maintenance, making it suitable for enriching otherwise cached catalogs.
 func (c *Client) GetTracksInMaintenance() ([]string, error) {
+	return c.GetTracksInMaintenanceCtx(c.context())
+}
+
+// GetTracksInMaintenanceCtx is like GetTracksInMaintenance but uses the given context instead of the client's Context.
+func (c *Client) GetTracksInMaintenanceCtx(ctx context.Context) (slugs []string, err error) {
+	ctx, end := c.startOperation(ctx, "GetTracksInMaintenance")
+	defer func() { end(err) }()
+
 	var q tracksInMaintenanceQuery
 	variables := map[string]interface{}{
 		"organizationSlug": graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetTracksInMaintenance", &q, variables); err != nil {
 		return nil, err
 	}
 
-	slugs := make([]string, 0)
+	slugs = make([]string, 0)
 	for _, track := range q.Tracks {
 		if track.Maintenance {
 			slugs = append(slugs, track.Slug)
@@ -381,13 +598,18 @@ func (c *Client) GetTracksInMaintenance() ([]string, error) {
 //   - string: The generated one-time play token.
 //   - error: Any error encountered while generating the token.
 func (c *Client) GenerateOneTimePlayToken(trackId string, opts ...Option) (token string, err error) {
+	return c.GenerateOneTimePlayTokenCtx(c.context(), trackId, opts...)
+}
+
+// GenerateOneTimePlayTokenCtx is like GenerateOneTimePlayToken but uses the given context instead of the client's Context.
+func (c *Client) GenerateOneTimePlayTokenCtx(ctx context.Context, trackId string, opts ...Option) (token string, err error) {
 	options := &options{}
 	for _, opt := range opts {
 		opt(options)
 	}
 
 	if options.userDetails != nil {
-		return c.generateOneTimePlayTokenWithUserDetails(trackId, *options.userDetails)
+		return c.generateOneTimePlayTokenWithUserDetails(ctx, trackId, *options.userDetails)
 	}
 
 	var m struct {
@@ -398,7 +620,7 @@ func (c *Client) GenerateOneTimePlayToken(trackId string, opts ...Option) (token
 		"trackID": graphql.String(trackId),
 	}
 
-	if err := c.GraphQLClient.Mutate(c.Context, &m, variables); err != nil {
+	if err := c.mutate(ctx, "GenerateOneTimePlayToken", &m, variables); err != nil {
 		return "", err
 	}
 
@@ -414,7 +636,7 @@ type OneTimeTokenUserDetailsInput struct {
 	Email     string `json:"email,omitempty"`
 }
 
-func (c *Client) generateOneTimePlayTokenWithUserDetails(trackId string, userDetails OneTimeTokenUserDetailsInput) (token string, err error) {
+func (c *Client) generateOneTimePlayTokenWithUserDetails(ctx context.Context, trackId string, userDetails OneTimeTokenUserDetailsInput) (token string, err error) {
 	var m struct {
 		GenerateOneTimePlayToken string `graphql:"generateOneTimePlayToken(trackID: $trackID, userDetails: $userDetails)"`
 	}
@@ -424,7 +646,7 @@ func (c *Client) generateOneTimePlayTokenWithUserDetails(trackId string, userDet
 		"userDetails": userDetails,
 	}
 
-	if err := c.GraphQLClient.Mutate(c.Context, &m, variables); err != nil {
+	if err := c.mutate(ctx, "GenerateOneTimePlayToken", &m, variables); err != nil {
 		return "", err
 	}
 
@@ -442,6 +664,11 @@ func (c *Client) generateOneTimePlayTokenWithUserDetails(trackId string, userDet
 // - []Review: A list retrieved Reviews. Includes Play if specified.
 // - error: An error object if the query fails or the review is not found.
 func (c *Client) GetReviews(trackId string, opts ...Option) (count int, reviews []Review, err error) {
+	return c.GetReviewsCtx(c.context(), trackId, opts...)
+}
+
+// GetReviewsCtx is like GetReviews but uses the given context instead of the client's Context.
+func (c *Client) GetReviewsCtx(ctx context.Context, trackId string, opts ...Option) (count int, reviews []Review, err error) {
 	// Initialize default options.
 	options := &options{}
 	for _, opt := range opts {
@@ -463,8 +690,8 @@ func (c *Client) GetReviews(trackId string, opts ...Option) (count int, reviews
 		}
 
 		// Execute the query.
-		if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-			return 0, nil, fmt.Errorf("GraphQL query with play failed: %w", err)
+		if err := c.query(ctx, "GetReviews", &q, variables); err != nil {
+			return 0, nil, err
 		}
 
 		// Return the fetched Review, which includes Play.
@@ -480,8 +707,8 @@ func (c *Client) GetReviews(trackId string, opts ...Option) (count int, reviews
 	}
 
 	// Execute the query.
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return 0, nil, fmt.Errorf("GraphQL query failed: %w", err)
+	if err := c.query(ctx, "GetReviews", &q, variables); err != nil {
+		return 0, nil, err
 	}
 
 	// Construct the Reviews without Play.
@@ -508,6 +735,11 @@ type challengesQuery struct {
 //   - []Challenge: The list of challenges.
 //   - error: Any error encountered while retrieving the challenge.
 func (c *Client) GetChallenges(trackId string) (ch []Challenge, err error) {
+	return c.GetChallengesCtx(c.context(), trackId)
+}
+
+// GetChallengesCtx is like GetChallenges but uses the given context instead of the client's Context.
+func (c *Client) GetChallengesCtx(ctx context.Context, trackId string) (ch []Challenge, err error) {
 	if trackId == "" {
 		return ch, nil
 	}
@@ -518,9 +750,195 @@ func (c *Client) GetChallenges(trackId string) (ch []Challenge, err error) {
 		"teamSlug": graphql.String(c.TeamSlug),
 	}
 
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
+	if err := c.query(ctx, "GetChallenges", &q, variables); err != nil {
 		return ch, err
 	}
 
 	return q.Challenges, nil
 }
+
+// TrackInput holds the metadata of a track to create or update. Empty fields
+// are left unchanged by UpdateTrack.
+type TrackInput struct {
+	Id          string   `json:"id,omitempty"`          // The unique identifier of the track, set by UpdateTrack.
+	Slug        string   `json:"slug,omitempty"`        // The slug identifier for the track.
+	Title       string   `json:"title,omitempty"`       // The title of the track.
+	Teaser      string   `json:"teaser,omitempty"`      // A teaser or short description of the track.
+	Description string   `json:"description,omitempty"` // The description of the track.
+	Level       string   `json:"level,omitempty"`       // The difficulty level of the track.
+	Icon        string   `json:"icon,omitempty"`        // The icon associated with the track.
+	Tags        []string `json:"tags,omitempty"`        // The tags of the track.
+	Maintenance *bool    `json:"maintenance,omitempty"` // Whether the track is in maintenance mode.
+}
+
+// createTrackMutation represents the GraphQL mutation to create a track.
+type createTrackMutation struct {
+	CreateTrack Track `graphql:"createTrack(teamSlug: $teamSlug, track: $track)"`
+}
+
+// updateTrackMutation represents the GraphQL mutation to update a track.
+type updateTrackMutation struct {
+	UpdateTrack Track `graphql:"updateTrack(track: $track)"`
+}
+
+// archiveTrackMutation represents the GraphQL mutation to archive a track.
+type archiveTrackMutation struct {
+	ArchiveTrack Track `graphql:"archiveTrack(trackID: $trackID)"`
+}
+
+// deleteTrackMutation represents the GraphQL mutation to delete a track.
+type deleteTrackMutation struct {
+	DeleteTrack bool `graphql:"deleteTrack(trackID: $trackID)"`
+}
+
+// CreateTrack creates a track for the team.
+//
+// Parameters:
+//   - input: The metadata of the track. Slug and Title are required.
+//
+// Returns:
+//   - Track: The created track.
+//   - error: Any error encountered while creating the track.
+func (c *Client) CreateTrack(input TrackInput) (t Track, err error) {
+	return c.CreateTrackCtx(c.context(), input)
+}
+
+// CreateTrackCtx is like CreateTrack but uses the given context instead of the client's Context.
+func (c *Client) CreateTrackCtx(ctx context.Context, input TrackInput) (t Track, err error) {
+	if input.Slug == "" || input.Title == "" {
+		return t, fmt.Errorf("%w: a track needs a slug and a title", ErrValidation)
+	}
+	input.Id = ""
+
+	var m createTrackMutation
+	variables := map[string]interface{}{
+		"teamSlug": graphql.String(c.TeamSlug),
+		"track":    input,
+	}
+
+	if err := c.mutate(ctx, "CreateTrack", &m, variables); err != nil {
+		return t, err
+	}
+	c.InvalidateCache(CacheTracks)
+
+	return m.CreateTrack, nil
+}
+
+// UpdateTrack updates the metadata of a track. Fields left empty in the
+// input are not changed.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track to update.
+//   - input: The metadata to set on the track.
+//
+// Returns:
+//   - Track: The updated track.
+//   - error: Any error encountered while updating the track.
+func (c *Client) UpdateTrack(trackId string, input TrackInput) (t Track, err error) {
+	return c.UpdateTrackCtx(c.context(), trackId, input)
+}
+
+// UpdateTrackCtx is like UpdateTrack but uses the given context instead of the client's Context.
+func (c *Client) UpdateTrackCtx(ctx context.Context, trackId string, input TrackInput) (t Track, err error) {
+	if trackId == "" {
+		return t, fmt.Errorf("%w: missing track ID", ErrValidation)
+	}
+	input.Id = trackId
+
+	var m updateTrackMutation
+	variables := map[string]interface{}{
+		"track": input,
+	}
+
+	if err := c.mutate(ctx, "UpdateTrack", &m, variables); err != nil {
+		return t, err
+	}
+	c.InvalidateCache(CacheTracks)
+
+	return m.UpdateTrack, nil
+}
+
+// SetTrackMaintenance places a track in maintenance mode, or takes it out of
+// it. Tracks in maintenance cannot be started by learners, and are listed by
+// GetTracksInMaintenance.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track.
+//   - enabled: Whether the track should be in maintenance mode.
+//
+// Returns:
+//   - Track: The updated track.
+//   - error: Any error encountered while updating the track.
+func (c *Client) SetTrackMaintenance(trackId string, enabled bool) (t Track, err error) {
+	return c.SetTrackMaintenanceCtx(c.context(), trackId, enabled)
+}
+
+// SetTrackMaintenanceCtx is like SetTrackMaintenance but uses the given context instead of the client's Context.
+func (c *Client) SetTrackMaintenanceCtx(ctx context.Context, trackId string, enabled bool) (t Track, err error) {
+	return c.UpdateTrackCtx(ctx, trackId, TrackInput{Maintenance: &enabled})
+}
+
+// ArchiveTrack archives a track, hiding it from the catalog while keeping its
+// content and play history. The returned track has DeletedAt set.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track to archive.
+//
+// Returns:
+//   - Track: The archived track.
+//   - error: Any error encountered while archiving the track.
+func (c *Client) ArchiveTrack(trackId string) (t Track, err error) {
+	return c.ArchiveTrackCtx(c.context(), trackId)
+}
+
+// ArchiveTrackCtx is like ArchiveTrack but uses the given context instead of the client's Context.
+func (c *Client) ArchiveTrackCtx(ctx context.Context, trackId string) (t Track, err error) {
+	if trackId == "" {
+		return t, fmt.Errorf("%w: missing track ID", ErrValidation)
+	}
+
+	var m archiveTrackMutation
+	variables := map[string]interface{}{
+		"trackID": graphql.String(trackId),
+	}
+
+	if err := c.mutate(ctx, "ArchiveTrack", &m, variables); err != nil {
+		return t, err
+	}
+	c.InvalidateCache(CacheTracks)
+
+	return m.ArchiveTrack, nil
+}
+
+// DeleteTrack permanently deletes a track along with its challenges.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track to delete.
+//
+// Returns:
+//   - error: Any error encountered while deleting the track.
+func (c *Client) DeleteTrack(trackId string) error {
+	return c.DeleteTrackCtx(c.context(), trackId)
+}
+
+// DeleteTrackCtx is like DeleteTrack but uses the given context instead of the client's Context.
+func (c *Client) DeleteTrackCtx(ctx context.Context, trackId string) error {
+	if trackId == "" {
+		return fmt.Errorf("%w: missing track ID", ErrValidation)
+	}
+
+	var m deleteTrackMutation
+	variables := map[string]interface{}{
+		"trackID": graphql.String(trackId),
+	}
+
+	if err := c.mutate(ctx, "DeleteTrack", &m, variables); err != nil {
+		return err
+	}
+	c.InvalidateCache(CacheTracks, CacheChallenges)
+
+	if !m.DeleteTrack {
+		return fmt.Errorf("failed to delete track %s", trackId)
+	}
+	return nil
+}
@@ -15,7 +15,9 @@
 package instruqt
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
 	"github.com/stretchr/testify/assert"
@@ -290,3 +292,154 @@ func TestGenerateOneTimePlayTokenWithUserDetails(t *testing.T) {
 	assert.Equal(t, expectedToken, token)
 	mockClient.AssertExpectations(t)
 }
+
+func TestGetTrackByIdCtx_PropagatesContext(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		Context:       context.Background(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Every nested query must receive the per-call context.
+	mockClient.On("Query", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := client.GetTrackByIdCtx(ctx, "track-123", WithChallenges(), WithReviews())
+
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "Query", 3)
+}
+
+func TestCreateTrack(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		TeamSlug:      "isovalent",
+	}
+
+	input := TrackInput{Slug: "new-track", Title: "New Track", Tags: []string{"cilium"}}
+	mockClient.On("Mutate", mock.Anything, &createTrackMutation{}, map[string]interface{}{
+		"teamSlug": graphql.String("isovalent"),
+		"track":    input,
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*createTrackMutation)
+		m.CreateTrack = Track{Id: "track-123", Slug: "new-track", Title: "New Track"}
+	}).Return(nil)
+
+	track, err := client.CreateTrack(input)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "track-123", track.Id)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreateTrack_Validation(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	_, err := client.CreateTrack(TrackInput{Title: "No Slug"})
+
+	assert.ErrorIs(t, err, ErrValidation)
+	mockClient.AssertNotCalled(t, "Mutate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTrack(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &updateTrackMutation{}, map[string]interface{}{
+		"track": TrackInput{Id: "track-123", Title: "Renamed", Level: "beginner"},
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*updateTrackMutation)
+		m.UpdateTrack = Track{Id: "track-123", Title: "Renamed", Level: "beginner"}
+	}).Return(nil)
+
+	track, err := client.UpdateTrack("track-123", TrackInput{Title: "Renamed", Level: "beginner"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", track.Title)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSetTrackMaintenance(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Mutate", mock.Anything, &updateTrackMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+		input := args.Get(2).(map[string]interface{})["track"].(TrackInput)
+		assert.Equal(t, "track-123", input.Id)
+		if assert.NotNil(t, input.Maintenance) {
+			assert.True(t, *input.Maintenance)
+		}
+		assert.Empty(t, input.Title, "Expected other fields to be left unchanged")
+	}).Return(nil)
+
+	_, err := client.SetTrackMaintenance("track-123", true)
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDeleteTrack(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := newCachedClient(mockClient, NewLRUCache(10))
+
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Return(nil)
+	mockClient.On("Mutate", mock.Anything, &deleteTrackMutation{}, map[string]interface{}{
+		"trackID": graphql.String("track-123"),
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*deleteTrackMutation)
+		m.DeleteTrack = true
+	}).Return(nil)
+
+	_, _ = client.GetTrackById("track-123")
+	err := client.DeleteTrack("track-123")
+	assert.NoError(t, err)
+	_, _ = client.GetTrackById("track-123")
+
+	mockClient.AssertNumberOfCalls(t, "Query", 2)
+}
+
+func TestArchiveTrack(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	archivedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockClient.On("Mutate", mock.Anything, &archiveTrackMutation{}, map[string]interface{}{
+		"trackID": graphql.String("track-123"),
+	}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*archiveTrackMutation)
+		m.ArchiveTrack = Track{Id: "track-123", DeletedAt: archivedAt}
+	}).Return(nil)
+
+	track, err := client.ArchiveTrack("track-123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, archivedAt, track.DeletedAt)
+	mockClient.AssertExpectations(t)
+}
+
+func TestArchiveAndDeleteTrack_MissingID(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	_, err := client.ArchiveTrack("")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	err = client.DeleteTrack("")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	mockClient.AssertNotCalled(t, "Mutate", mock.Anything, mock.Anything, mock.Anything)
+}
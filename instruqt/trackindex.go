@@ -0,0 +1,458 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TrackSort defines the order of the tracks returned by TrackIndex.Search.
+type TrackSort string
+
+// Constants representing the orders of search results.
+const (
+	TrackSortRelevance   TrackSort = ""             // By text relevance, then title. This is the default.
+	TrackSortReviewScore TrackSort = "review_score" // By Statistics.Average_review_score.
+	TrackSortCreatedAt   TrackSort = "created_at"   // By CreatedAt.
+	TrackSortLastUpdate  TrackSort = "last_update"  // By Last_Update.
+)
+
+// Weights of the fields searched by TrackIndex.Search.
+var trackFieldWeights = [...]int{
+	3, // Title
+	2, // Teaser
+	1, // Description
+	1, // Challenge titles
+}
+
+// TrackQuery holds the criteria of a TrackIndex search. Empty criteria match
+// every track.
+type TrackQuery struct {
+	Text        string    // Words that must all appear in the title, teaser, description or challenge titles. The last word may be a prefix.
+	Levels      []string  // Levels, any of which the track must have.
+	Tags        []string  // Tags, all of which the track must have.
+	Maintenance *bool     // Whether the track must be in maintenance mode, or not.
+	SortBy      TrackSort // The order of the results.
+	Ascending   bool      // Whether to sort in ascending order. Results are sorted in descending order by default.
+	Offset      int       // The number of results to skip.
+	Limit       int       // The maximum number of results to return. Zero means no limit.
+}
+
+// TrackFacets holds the number of matching tracks for each value of a facet.
+// The level and maintenance facets are counted ignoring their own criteria,
+// so that the counts show how many tracks selecting another value would
+// match. Since a track must have all the tags of a query, the tag facet is
+// counted over the tracks matching every criterion, showing how many of them
+// would remain if a tag were added.
+type TrackFacets struct {
+	Levels        map[string]int `json:"levels"`         // Tracks by level.
+	Tags          map[string]int `json:"tags"`           // Tracks by tag.
+	InMaintenance int            `json:"in_maintenance"` // Tracks in maintenance mode.
+	Available     int            `json:"available"`      // Tracks not in maintenance mode.
+}
+
+// TrackSearchResult holds the results of a TrackIndex search.
+type TrackSearchResult struct {
+	Tracks []Track     `json:"tracks"` // The matching tracks, sorted, offset and limited.
+	Total  int         `json:"total"`  // The number of matching tracks.
+	Facets TrackFacets `json:"facets"` // The facets of the tracks matching the text.
+}
+
+// TrackIndex is an in-memory index of tracks supporting text search, facets
+// and sorting. It can be built from a list of tracks or a Catalog and kept
+// up to date incrementally, by applying newer catalogs or refreshing the
+// tracks made stale by webhook events. Archived tracks are not indexed.
+//
+// A TrackIndex is safe for concurrent use.
+type TrackIndex struct {
+	mu     sync.RWMutex
+	tracks map[string]*indexedTrack // By track ID.
+	stale  map[string]bool          // IDs of the tracks to refresh.
+}
+
+// indexedTrack is a track along with its search terms.
+type indexedTrack struct {
+	track       Track
+	maintenance bool
+	terms       [len(trackFieldWeights)][]string // The sorted terms of each searched field.
+	tags        []string
+}
+
+// NewTrackIndex builds an index of tracks.
+//
+// Parameters:
+//   - tracks: The tracks to index, with their challenges to search their titles.
+//   - inMaintenance: The slugs of the tracks in maintenance, as returned by GetTracksInMaintenance.
+//
+// Returns:
+//   - *TrackIndex: The index.
+func NewTrackIndex(tracks []Track, inMaintenance []string) *TrackIndex {
+	ix := &TrackIndex{
+		tracks: make(map[string]*indexedTrack, len(tracks)),
+		stale:  make(map[string]bool),
+	}
+	for _, t := range tracks {
+		ix.upsert(t, slices.Contains(inMaintenance, t.Slug))
+	}
+	return ix
+}
+
+// NewTrackIndexFromCatalog builds an index of the tracks of a catalog.
+func NewTrackIndexFromCatalog(catalog *Catalog) *TrackIndex {
+	return NewTrackIndex(catalog.Tracks, catalog.TracksInMaintenance)
+}
+
+// Len returns the number of indexed tracks.
+func (ix *TrackIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.tracks)
+}
+
+// Upsert adds a track to the index, or replaces it. An archived track is
+// removed instead.
+func (ix *TrackIndex) Upsert(track Track, maintenance bool) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.upsert(track, maintenance)
+}
+
+// Remove removes a track from the index.
+func (ix *TrackIndex) Remove(trackId string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	delete(ix.tracks, trackId)
+	delete(ix.stale, trackId)
+}
+
+// SetMaintenance updates the maintenance state of an indexed track.
+func (ix *TrackIndex) SetMaintenance(trackId string, maintenance bool) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if t, ok := ix.tracks[trackId]; ok {
+		t.maintenance = maintenance
+	}
+}
+
+// ApplyCatalog updates the index from a newer catalog, re-indexing only the
+// tracks that were added, removed or changed since the indexed state,
+// including changes to their challenges and maintenance state. The tracks of
+// the catalog are no longer stale. Archived tracks are left out, so that an
+// indexed track that was archived is reported as removed.
+//
+// Parameters:
+//   - catalog: The newer catalog, as returned by GetCatalog.
+//
+// Returns:
+//   - CatalogDiff: The changes applied to the index, as reported by Diff.
+//     Invites are not indexed, so their changes are not reported.
+func (ix *TrackIndex) ApplyCatalog(catalog *Catalog) CatalogDiff {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	indexed := &Catalog{}
+	for _, t := range ix.tracks {
+		indexed.Tracks = append(indexed.Tracks, t.track)
+		if t.maintenance {
+			indexed.TracksInMaintenance = append(indexed.TracksInMaintenance, t.track.Slug)
+		}
+	}
+	active := slices.DeleteFunc(slices.Clone(catalog.Tracks), func(t Track) bool { return !t.DeletedAt.IsZero() })
+	d := Diff(indexed, &Catalog{Tracks: active, TracksInMaintenance: catalog.TracksInMaintenance})
+
+	changed := make(map[string]bool)
+	for _, change := range d.Tracks {
+		changed[change.Id] = true
+	}
+	for _, change := range d.Challenges {
+		changed[change.TrackId] = true
+	}
+
+	for _, t := range catalog.Tracks {
+		delete(ix.stale, t.Id)
+	}
+	for _, t := range active {
+		// Diff ignores review scores and update times, which are sorted on.
+		if old, ok := ix.tracks[t.Id]; ok && (old.track.Statistics != t.Statistics || !old.track.Last_Update.Equal(t.Last_Update)) {
+			changed[t.Id] = true
+		}
+		if changed[t.Id] {
+			ix.upsert(t, slices.Contains(catalog.TracksInMaintenance, t.Slug))
+			delete(changed, t.Id)
+		}
+	}
+	// The remaining tracks were removed from the catalog.
+	for id := range changed {
+		delete(ix.tracks, id)
+		delete(ix.stale, id)
+	}
+
+	return d
+}
+
+// HandleEvent marks the tracks made stale by a webhook event, to be fetched
+// again by Refresh: review events change the review score of their track,
+// and events of unknown tracks reveal new tracks.
+func (ix *TrackIndex) HandleEvent(event WebhookEvent) {
+	if event.TrackId == "" {
+		return
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	kind, _, _ := strings.Cut(event.Type, ".")
+	if _, known := ix.tracks[event.TrackId]; kind == "review" || !known {
+		ix.stale[event.TrackId] = true
+	}
+}
+
+// WebhookHandler wraps a WebhookHandler so that the index is updated with
+// HandleEvent before each event is handled.
+//
+// Usage: http.Handle("/webhook", HandleWebhook(index.WebhookHandler(handler), secret))
+func (ix *TrackIndex) WebhookHandler(handler WebhookHandler) WebhookHandler {
+	return func(w http.ResponseWriter, r *http.Request, webhook WebhookEvent) error {
+		ix.HandleEvent(webhook)
+		return handler(w, r, webhook)
+	}
+}
+
+// Stale returns the IDs of the tracks marked stale by HandleEvent, sorted.
+func (ix *TrackIndex) Stale() []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	ids := make([]string, 0, len(ix.stale))
+	for id := range ix.stale {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Refresh fetches the stale tracks again, with their challenges, and
+// re-indexes them. Tracks that no longer exist are removed. Tracks that
+// could not be fetched stay stale.
+//
+// Parameters:
+//   - c: The client used to fetch the tracks.
+//   - opts: Optional settings, such as WithConcurrency.
+//
+// Returns:
+//   - error: The joined errors of the tracks that could not be fetched.
+func (ix *TrackIndex) Refresh(c *Client, opts ...Option) error {
+	return ix.RefreshCtx(c.context(), c, opts...)
+}
+
+// RefreshCtx is like Refresh but uses the given context instead of the client's Context.
+func (ix *TrackIndex) RefreshCtx(ctx context.Context, c *Client, opts ...Option) error {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	ids := ix.Stale()
+	tracks := make([]*Track, len(ids))
+	missing := make([]bool, len(ids))
+	err := fanOut(ctx, len(ids), options.concurrency, ErrorPolicyCollectAll, func(ctx context.Context, i int) error {
+		track, err := c.GetTrackByIdCtx(ctx, ids[i], WithChallenges())
+		switch {
+		case errors.Is(err, ErrNotFound):
+			missing[i] = true
+		case err != nil:
+			return fmt.Errorf("failed to refresh track %s: %w", ids[i], err)
+		default:
+			tracks[i] = &track
+		}
+		return nil
+	})
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for i, id := range ids {
+		switch {
+		case missing[i]:
+			delete(ix.tracks, id)
+			delete(ix.stale, id)
+		case tracks[i] != nil:
+			// Tracks fetched by ID do not carry their maintenance state.
+			maintenance := false
+			if t, ok := ix.tracks[id]; ok {
+				maintenance = t.maintenance
+			}
+			ix.upsert(*tracks[i], maintenance)
+			delete(ix.stale, id)
+		}
+	}
+	return err
+}
+
+// Search returns the tracks matching a query, along with their facets.
+func (ix *TrackIndex) Search(q TrackQuery) TrackSearchResult {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	words := tokenize(q.Text)
+	last, _ := utf8.DecodeLastRuneInString(q.Text)
+	prefix := q.Text != "" && !unicode.IsSpace(last)
+
+	type match struct {
+		track *indexedTrack
+		score int
+	}
+	var matches []match
+	result := TrackSearchResult{
+		Facets: TrackFacets{
+			Levels: make(map[string]int),
+			Tags:   make(map[string]int),
+		},
+	}
+
+	for _, t := range ix.tracks {
+		score, ok := t.textScore(words, prefix)
+		if !ok {
+			continue
+		}
+
+		levelOK := len(q.Levels) == 0 || slices.Contains(q.Levels, t.track.Level)
+		tagsOK := true
+		for _, tag := range q.Tags {
+			tagsOK = tagsOK && slices.Contains(t.tags, tag)
+		}
+		maintenanceOK := q.Maintenance == nil || *q.Maintenance == t.maintenance
+
+		if tagsOK && maintenanceOK {
+			result.Facets.Levels[t.track.Level]++
+		}
+		if levelOK && maintenanceOK && tagsOK {
+			for _, tag := range t.tags {
+				result.Facets.Tags[tag]++
+			}
+		}
+		if levelOK && tagsOK {
+			if t.maintenance {
+				result.Facets.InMaintenance++
+			} else {
+				result.Facets.Available++
+			}
+		}
+
+		if levelOK && tagsOK && maintenanceOK {
+			matches = append(matches, match{track: t, score: score})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b match) int {
+		var c int
+		switch q.SortBy {
+		case TrackSortReviewScore:
+			c = cmp.Compare(a.track.track.Statistics.Average_review_score, b.track.track.Statistics.Average_review_score)
+		case TrackSortCreatedAt:
+			c = a.track.track.CreatedAt.Compare(b.track.track.CreatedAt)
+		case TrackSortLastUpdate:
+			c = a.track.track.Last_Update.Compare(b.track.track.Last_Update)
+		default:
+			c = cmp.Compare(a.score, b.score)
+		}
+		if !q.Ascending {
+			c = -c
+		}
+		// Break ties by title, then ID, so that results are stable.
+		return cmp.Or(c,
+			cmp.Compare(a.track.track.Title, b.track.track.Title),
+			cmp.Compare(a.track.track.Id, b.track.track.Id))
+	})
+
+	result.Total = len(matches)
+	start := min(max(q.Offset, 0), len(matches))
+	end := len(matches)
+	if q.Limit > 0 {
+		end = min(start+q.Limit, end)
+	}
+	result.Tracks = make([]Track, 0, end-start)
+	for _, m := range matches[start:end] {
+		result.Tracks = append(result.Tracks, m.track.track)
+	}
+	return result
+}
+
+// upsert indexes a track. The caller must hold the lock.
+func (ix *TrackIndex) upsert(track Track, maintenance bool) {
+	if !track.DeletedAt.IsZero() {
+		delete(ix.tracks, track.Id)
+		return
+	}
+
+	t := &indexedTrack{track: track, maintenance: maintenance}
+	var challengeTitles []string
+	for _, ch := range track.Challenges {
+		challengeTitles = append(challengeTitles, ch.Title)
+	}
+	for i, text := range []string{track.Title, track.Teaser, track.Description, strings.Join(challengeTitles, " ")} {
+		terms := tokenize(text)
+		slices.Sort(terms)
+		t.terms[i] = slices.Compact(terms)
+	}
+	for _, tag := range track.TrackTags {
+		t.tags = append(t.tags, tag.Value)
+	}
+	ix.tracks[track.Id] = t
+}
+
+// textScore reports whether every word appears in one of the searched
+// fields of the track, and scores the match by the weights of the fields
+// where they appear. With prefix, the last word may be the prefix of a term.
+func (t *indexedTrack) textScore(words []string, prefix bool) (int, bool) {
+	score := 0
+	for i, word := range words {
+		isPrefix := prefix && i == len(words)-1
+		found := false
+		for field, terms := range t.terms {
+			if hasTerm(terms, word, isPrefix) {
+				score += trackFieldWeights[field]
+				found = true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// hasTerm reports whether the sorted terms contain word, or a term starting
+// with word if prefix is set.
+func hasTerm(terms []string, word string, prefix bool) bool {
+	i, found := slices.BinarySearch(terms, word)
+	return found || (prefix && i < len(terms) && strings.HasPrefix(terms[i], word))
+}
+
+// tokenize splits text into lower-case words of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testIndexTracks() []Track {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tracks := []Track{
+		{Id: "t1", Slug: "cilium-101", Title: "Getting Started with Cilium", Teaser: "Networking basics", Level: "beginner", CreatedAt: day(1), Last_Update: day(9),
+			TrackTags: []TrackTag{{Value: "cilium"}}, Challenges: []Challenge{{Id: "c1", Title: "Install Cilium"}, {Id: "c2", Title: "Observe flows with Hubble"}}},
+		{Id: "t2", Slug: "tetragon-101", Title: "Getting Started with Tetragon", Description: "Security observability with eBPF.", Level: "beginner", CreatedAt: day(2), Last_Update: day(3),
+			TrackTags: []TrackTag{{Value: "tetragon"}, {Value: "ebpf"}}},
+		{Id: "t3", Slug: "cilium-advanced", Title: "Advanced Cilium Networking", Level: "advanced", CreatedAt: day(3), Last_Update: day(4),
+			TrackTags: []TrackTag{{Value: "cilium"}, {Value: "ebpf"}}},
+	}
+	tracks[0].Statistics.Average_review_score = 4.5
+	tracks[1].Statistics.Average_review_score = 4.9
+	tracks[2].Statistics.Average_review_score = 3.8
+	return tracks
+}
+
+func trackIds(tracks []Track) []string {
+	ids := make([]string, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.Id
+	}
+	return ids
+}
+
+func TestTrackIndex_Search(t *testing.T) {
+	ix := NewTrackIndex(testIndexTracks(), []string{"cilium-advanced"})
+
+	// Matches in several fields score higher.
+	result := ix.Search(TrackQuery{Text: "cilium"})
+	assert.Equal(t, []string{"t1", "t3"}, trackIds(result.Tracks))
+
+	// Title matches weigh more than teaser matches.
+	result = ix.Search(TrackQuery{Text: "networking"})
+	assert.Equal(t, []string{"t3", "t1"}, trackIds(result.Tracks))
+
+	result = ix.Search(TrackQuery{Text: "hubble"})
+	assert.Equal(t, []string{"t1"}, trackIds(result.Tracks), "Expected challenge titles to be searched")
+
+	result = ix.Search(TrackQuery{Text: "getting sec"})
+	assert.Equal(t, []string{"t2"}, trackIds(result.Tracks), "Expected the last word to match as a prefix")
+
+	result = ix.Search(TrackQuery{Text: "sec "})
+	assert.Empty(t, result.Tracks, "Expected complete words to match exactly")
+
+	result = ix.Search(TrackQuery{Text: "sec\u3000"})
+	assert.Empty(t, result.Tracks, "Expected a trailing multibyte space to end the last word")
+}
+
+func TestTrackIndex_Facets(t *testing.T) {
+	ix := NewTrackIndex(testIndexTracks(), []string{"cilium-advanced"})
+	inMaintenance := false
+
+	result := ix.Search(TrackQuery{Levels: []string{"beginner"}, Tags: []string{"ebpf"}, Maintenance: &inMaintenance})
+
+	assert.Equal(t, []string{"t2"}, trackIds(result.Tracks))
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, map[string]int{"beginner": 1}, result.Facets.Levels)
+	assert.Equal(t, map[string]int{"tetragon": 1, "ebpf": 1}, result.Facets.Tags)
+	assert.Equal(t, 1, result.Facets.Available)
+	assert.Equal(t, 0, result.Facets.InMaintenance)
+
+	result = ix.Search(TrackQuery{Tags: []string{"ebpf"}})
+	assert.Equal(t, map[string]int{"beginner": 1, "advanced": 1}, result.Facets.Levels)
+	assert.Equal(t, 1, result.Facets.InMaintenance)
+}
+
+func TestTrackIndex_Sort(t *testing.T) {
+	ix := NewTrackIndex(testIndexTracks(), nil)
+
+	assert.Equal(t, []string{"t2", "t1", "t3"}, trackIds(ix.Search(TrackQuery{SortBy: TrackSortReviewScore}).Tracks))
+	assert.Equal(t, []string{"t1", "t2", "t3"}, trackIds(ix.Search(TrackQuery{SortBy: TrackSortCreatedAt, Ascending: true}).Tracks))
+	assert.Equal(t, []string{"t1", "t3", "t2"}, trackIds(ix.Search(TrackQuery{SortBy: TrackSortLastUpdate}).Tracks))
+
+	result := ix.Search(TrackQuery{SortBy: TrackSortCreatedAt, Offset: 1, Limit: 1})
+	assert.Equal(t, []string{"t2"}, trackIds(result.Tracks))
+	assert.Equal(t, 3, result.Total)
+}
+
+func TestTrackIndex_ApplyCatalog(t *testing.T) {
+	tracks := testIndexTracks()
+	ix := NewTrackIndex(tracks, nil)
+
+	tracks = testIndexTracks()
+	tracks[0].Challenges[1].Title = "Inspect flows"
+	tracks[1].Statistics.Average_review_score = 2
+	tracks[2].DeletedAt = time.Now()
+	tracks = append(tracks, Track{Id: "t4", Slug: "hubble-101", Title: "Hubble"})
+	tracks = append(tracks, Track{Id: "t5", Slug: "legacy", Title: "Legacy", DeletedAt: time.Now()})
+
+	d := ix.ApplyCatalog(&Catalog{Tracks: tracks, TracksInMaintenance: []string{"hubble-101"}})
+
+	assert.Len(t, d.Challenges, 1)
+	kinds := make(map[string]ChangeKind)
+	for _, change := range d.Tracks {
+		kinds[change.Id] = change.Kind
+	}
+	assert.Equal(t, map[string]ChangeKind{"t3": ChangeRemoved, "t4": ChangeAdded}, kinds,
+		"Expected archived tracks to be reported as removed, or not at all if they were not indexed")
+	assert.Equal(t, 3, ix.Len(), "Expected the archived track to be removed")
+	assert.Empty(t, ix.Search(TrackQuery{Text: "hubble"}).Tracks[0].Challenges)
+	assert.Equal(t, []string{"t4"}, trackIds(ix.Search(TrackQuery{Text: "hubble"}).Tracks))
+	assert.Equal(t, []string{"t1", "t2", "t4"}, trackIds(ix.Search(TrackQuery{SortBy: TrackSortReviewScore}).Tracks))
+	assert.Equal(t, 1, ix.Search(TrackQuery{}).Facets.InMaintenance)
+}
+
+func TestTrackIndex_Refresh(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	ix := NewTrackIndex(testIndexTracks(), []string{"cilium-101"})
+	ix.HandleEvent(WebhookEvent{Type: "track.started", TrackId: "t1"})
+	ix.HandleEvent(WebhookEvent{Type: "review.created", TrackId: "t1"})
+	ix.HandleEvent(WebhookEvent{Type: "track.started", TrackId: "t2"})
+	ix.HandleEvent(WebhookEvent{Type: "track.started", TrackId: "t5"})
+	ix.HandleEvent(WebhookEvent{Type: "track.started", TrackId: "t6"})
+	assert.Equal(t, []string{"t1", "t5", "t6"}, ix.Stale())
+
+	trackID := func(id string) any {
+		return mock.MatchedBy(func(vars map[string]interface{}) bool { return vars["trackId"] == graphql.String(id) })
+	}
+	mockClient.On("Query", mock.Anything, &trackQuery{}, trackID("t1")).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*trackQuery)
+		q.Track = Track{Id: "t1", Slug: "cilium-101", Title: "Getting Started with Cilium"}
+		q.Track.Statistics.Average_review_score = 5
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &trackQuery{}, trackID("t5")).Return(ErrNotFound)
+	mockClient.On("Query", mock.Anything, &trackQuery{}, trackID("t6")).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Return(nil)
+
+	err := ix.Refresh(client)
+
+	assert.ErrorContains(t, err, "failed to refresh track t6")
+	assert.Equal(t, []string{"t6"}, ix.Stale())
+	result := ix.Search(TrackQuery{SortBy: TrackSortReviewScore})
+	assert.Equal(t, []string{"t1", "t2", "t3"}, trackIds(result.Tracks))
+	assert.Equal(t, 1, result.Facets.InMaintenance, "Expected the maintenance state to be kept")
+}
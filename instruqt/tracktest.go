@@ -0,0 +1,458 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultChallengeTimeout is the time a TrackTester allows to start,
+	// solve and check a challenge.
+	DefaultChallengeTimeout = 10 * time.Minute
+
+	// cleanupTimeout is the time allowed to stop the sandbox of a track test,
+	// even after the test was canceled.
+	cleanupTimeout = time.Minute
+)
+
+// TrackSession is the user and sandbox a track is played with.
+type TrackSession struct {
+	UserId    string // The unique identifier of the user playing the track.
+	SandboxId string // The unique identifier of the sandbox of the play.
+}
+
+// TrackLauncher starts a track for a test user, for instance by opening the
+// URL returned by LaunchURL with WithOneTimePlayToken in a headless browser,
+// and returns the user and sandbox playing it. A launcher that fails after
+// creating a sandbox should still return its ID, so that it is stopped.
+type TrackLauncher func(ctx context.Context, track Track) (TrackSession, error)
+
+// ChallengeTestStatus is the outcome of testing a challenge.
+type ChallengeTestStatus string
+
+// Constants representing the outcomes of testing a challenge.
+const (
+	ChallengeTestPassed  ChallengeTestStatus = "passed"  // The check passed after solving the challenge.
+	ChallengeTestFailed  ChallengeTestStatus = "failed"  // The check did not pass after solving the challenge.
+	ChallengeTestError   ChallengeTestStatus = "error"   // A mutation failed or the challenge timed out.
+	ChallengeTestSkipped ChallengeTestStatus = "skipped" // A previous challenge did not pass.
+)
+
+// ChallengeTestResult is the result of testing a challenge.
+type ChallengeTestResult struct {
+	ChallengeId   string              `json:"challenge_id"`       // The unique identifier of the challenge.
+	ChallengeSlug string              `json:"challenge_slug"`     // The slug of the challenge.
+	Title         string              `json:"title"`              // The title of the challenge.
+	Status        ChallengeTestStatus `json:"status"`             // The outcome of the test.
+	Duration      time.Duration       `json:"duration"`           // The time taken to play the challenge, in nanoseconds in JSON.
+	Messages      []string            `json:"messages,omitempty"` // The messages of the failed checks of the challenge, oldest first.
+	Error         string              `json:"error,omitempty"`    // The error that interrupted the test, if any.
+}
+
+// TrackTestResult is the result of testing a track.
+type TrackTestResult struct {
+	TrackId      string                `json:"track_id"`                // The unique identifier of the track.
+	TrackSlug    string                `json:"track_slug"`              // The slug of the track.
+	Session      TrackSession          `json:"session"`                 // The user and sandbox the track was played with.
+	StartedAt    time.Time             `json:"started_at"`              // The time the test started.
+	Duration     time.Duration         `json:"duration"`                // The time taken by the test, cleanup included, in nanoseconds in JSON.
+	Challenges   []ChallengeTestResult `json:"challenges"`              // The results of the challenges, in track order.
+	Error        string                `json:"error,omitempty"`         // The error that prevented playing the track, if any.
+	CleanupError string                `json:"cleanup_error,omitempty"` // The error returned when stopping the sandbox, if any.
+}
+
+// Passed returns whether the track was played and every challenge passed.
+func (r *TrackTestResult) Passed() bool {
+	if r.Error != "" || r.CleanupError != "" {
+		return false
+	}
+	for _, ch := range r.Challenges {
+		if ch.Status != ChallengeTestPassed {
+			return false
+		}
+	}
+	return true
+}
+
+// TrackTestReport is the report of a TrackTester run.
+type TrackTestReport struct {
+	StartedAt time.Time         `json:"started_at"` // The time the run started.
+	Duration  time.Duration     `json:"duration"`   // The time taken by the run, in nanoseconds in JSON.
+	Tracks    []TrackTestResult `json:"tracks"`     // The results of the tracks, in the order they were given.
+}
+
+// Passed returns whether every track of the report passed.
+func (r *TrackTestReport) Passed() bool {
+	for i := range r.Tracks {
+		if !r.Tracks[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *TrackTestReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// TrackTesterOption defines a functional option for configuring a TrackTester.
+type TrackTesterOption func(*TrackTester)
+
+// WithTrackConcurrency sets the number of tracks tested in parallel, each
+// with its own sandbox.
+// Usage: NewTrackTester(client, launch, WithTrackConcurrency(2))
+func WithTrackConcurrency(n int) TrackTesterOption {
+	return func(t *TrackTester) {
+		t.concurrency = n
+	}
+}
+
+// WithChallengeTimeout sets the time allowed to start, solve and check each
+// challenge. The default is DefaultChallengeTimeout.
+// Usage: NewTrackTester(client, launch, WithChallengeTimeout(5*time.Minute))
+func WithChallengeTimeout(timeout time.Duration) TrackTesterOption {
+	return func(t *TrackTester) {
+		t.challengeTimeout = timeout
+	}
+}
+
+// TrackTester plays tracks end to end to check that every challenge passes.
+// Each track is started with a TrackLauncher, then each challenge is
+// started, solved with its solve scripts and checked, in order. Challenges
+// of type "quiz" are completed instead. The sandbox of each track is stopped
+// once the track was played, whatever the outcome.
+type TrackTester struct {
+	client           *Client
+	launch           TrackLauncher
+	concurrency      int
+	challengeTimeout time.Duration
+}
+
+// NewTrackTester creates a TrackTester playing tracks with the given client.
+//
+// Parameters:
+//   - c: The client used to play the tracks.
+//   - launch: The function starting a track for a test user.
+//   - opts: Optional settings, such as WithTrackConcurrency or WithChallengeTimeout.
+//
+// Returns:
+//   - *TrackTester: The track tester.
+func NewTrackTester(c *Client, launch TrackLauncher, opts ...TrackTesterOption) *TrackTester {
+	t := &TrackTester{
+		client:           c,
+		launch:           launch,
+		challengeTimeout: DefaultChallengeTimeout,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Run tests a matrix of tracks. A track given several times is played
+// several times, in separate sandboxes.
+//
+// Parameters:
+//   - trackIds: The unique identifiers of the tracks to test.
+//
+// Returns:
+//   - *TrackTestReport: The report of the run, also returned with an error.
+//   - error: The error of the context if the run was interrupted.
+func (t *TrackTester) Run(trackIds ...string) (*TrackTestReport, error) {
+	return t.RunCtx(t.client.context(), trackIds...)
+}
+
+// RunCtx is like Run but uses the given context instead of the client's Context.
+func (t *TrackTester) RunCtx(ctx context.Context, trackIds ...string) (*TrackTestReport, error) {
+	report := &TrackTestReport{
+		StartedAt: time.Now().UTC(),
+		Tracks:    make([]TrackTestResult, len(trackIds)),
+	}
+	for i, id := range trackIds {
+		report.Tracks[i].TrackId = id
+	}
+
+	err := fanOut(ctx, len(trackIds), t.concurrency, ErrorPolicyCollectAll, func(ctx context.Context, i int) error {
+		t.testTrack(ctx, &report.Tracks[i])
+		return nil
+	})
+	report.Duration = time.Since(report.StartedAt)
+
+	if err != nil {
+		for i := range report.Tracks {
+			if r := &report.Tracks[i]; r.StartedAt.IsZero() {
+				r.Error = fmt.Sprintf("not run: %v", err)
+			}
+		}
+	}
+	return report, err
+}
+
+// testTrack plays a track and stops its sandbox, recording the outcome in r.
+func (t *TrackTester) testTrack(ctx context.Context, r *TrackTestResult) {
+	r.StartedAt = time.Now().UTC()
+	defer func() { r.Duration = time.Since(r.StartedAt) }()
+
+	track, err := t.client.GetTrackByIdCtx(ctx, r.TrackId, WithChallenges())
+	if err != nil {
+		r.Error = fmt.Sprintf("failed to get track: %v", err)
+		return
+	}
+	r.TrackSlug = track.Slug
+
+	challenges := slices.Clone(track.Challenges)
+	slices.SortFunc(challenges, func(a, b Challenge) int { return a.Index - b.Index })
+	r.Challenges = make([]ChallengeTestResult, len(challenges))
+	for i, ch := range challenges {
+		r.Challenges[i] = ChallengeTestResult{
+			ChallengeId:   ch.Id,
+			ChallengeSlug: ch.Slug,
+			Title:         ch.Title,
+			Status:        ChallengeTestSkipped,
+		}
+	}
+
+	r.Session, err = t.launch(ctx, track)
+	if r.Session.SandboxId != "" {
+		defer func() {
+			// Stop the sandbox even if the test was canceled.
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+			defer cancel()
+			if err := t.client.StopSandboxCtx(ctx, r.Session.SandboxId); err != nil {
+				r.CleanupError = err.Error()
+			}
+		}()
+	}
+	if err != nil {
+		r.Error = fmt.Sprintf("failed to launch track: %v", err)
+		return
+	}
+
+	for i, ch := range challenges {
+		t.testChallenge(ctx, r.Session.UserId, track.Id, ch, &r.Challenges[i])
+		if r.Challenges[i].Status != ChallengeTestPassed {
+			// The following challenges stay locked.
+			return
+		}
+	}
+}
+
+// testChallenge plays a challenge within the challenge timeout, recording
+// the outcome in r.
+func (t *TrackTester) testChallenge(ctx context.Context, userId string, trackId string, ch Challenge, r *ChallengeTestResult) {
+	start := time.Now()
+	defer func() { r.Duration = time.Since(start) }()
+
+	ctx, cancel := context.WithTimeout(ctx, t.challengeTimeout)
+	defer cancel()
+
+	status, messages, err := t.playChallenge(ctx, userId, trackId, ch)
+	r.Status, r.Messages = status, messages
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s: %w", t.challengeTimeout, err)
+		}
+		r.Status, r.Error = ChallengeTestError, err.Error()
+	}
+}
+
+// playChallenge starts, solves and checks a challenge, or completes it if it
+// is a quiz.
+func (t *TrackTester) playChallenge(ctx context.Context, userId string, trackId string, ch Challenge) (ChallengeTestStatus, []string, error) {
+	if _, err := t.client.StartChallengeCtx(ctx, userId, trackId, ch.Id); err != nil {
+		return "", nil, fmt.Errorf("failed to start challenge: %w", err)
+	}
+
+	if ch.Type == "quiz" {
+		if _, err := t.client.CompleteChallengeCtx(ctx, userId, trackId, ch.Id); err != nil {
+			return "", nil, fmt.Errorf("failed to complete challenge: %w", err)
+		}
+		return ChallengeTestPassed, nil, nil
+	}
+
+	if _, err := t.client.SolveChallengeCtx(ctx, userId, trackId, ch.Id); err != nil {
+		return "", nil, fmt.Errorf("failed to solve challenge: %w", err)
+	}
+	check, err := t.client.CheckChallengeCtx(ctx, userId, trackId, ch.Id)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to check challenge: %w", err)
+	}
+
+	var messages []string
+	for _, a := range check.Challenge.Attempts {
+		if a.Message != "" {
+			messages = append(messages, a.Message)
+		}
+	}
+	if !check.Passed {
+		return ChallengeTestFailed, messages, nil
+	}
+	return ChallengeTestPassed, messages, nil
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is the test suite of a track in a JUnit XML report.
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty is a property of a test suite in a JUnit XML report.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase is the test case of a challenge in a JUnit XML report.
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	Classname string       `xml:"classname,attr"`
+	Time      string       `xml:"time,attr"`
+	Failure   *junitResult `xml:"failure"`
+	Error     *junitResult `xml:"error"`
+	Skipped   *junitResult `xml:"skipped"`
+}
+
+// junitResult is the failure, error or skipped element of a test case.
+type junitResult struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report in the JUnit XML format, with a test suite
+// per track and a test case per challenge. Failures to launch a track or to
+// stop its sandbox are reported as errors of the "launch" and "cleanup"
+// test cases.
+func (r *TrackTestReport) WriteJUnit(w io.Writer) error {
+	root := junitTestSuites{
+		Name: "instruqt",
+		Time: junitTime(r.Duration),
+	}
+
+	for _, track := range r.Tracks {
+		name := track.TrackSlug
+		if name == "" {
+			name = track.TrackId
+		}
+		suite := junitTestSuite{
+			Name:      name,
+			Time:      junitTime(track.Duration),
+			Timestamp: track.StartedAt.Format(time.RFC3339),
+			Properties: []junitProperty{
+				{Name: "track_id", Value: track.TrackId},
+				{Name: "user_id", Value: track.Session.UserId},
+				{Name: "sandbox_id", Value: track.Session.SandboxId},
+			},
+		}
+
+		if track.Error != "" {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "launch",
+				Classname: name,
+				Error:     &junitResult{Message: track.Error},
+			})
+		}
+		for _, ch := range track.Challenges {
+			tc := junitTestCase{
+				Name:      ch.ChallengeSlug,
+				Classname: name,
+				Time:      junitTime(ch.Duration),
+			}
+			switch ch.Status {
+			case ChallengeTestFailed:
+				message := "check failed"
+				if len(ch.Messages) > 0 {
+					message = ch.Messages[len(ch.Messages)-1]
+				}
+				tc.Failure = &junitResult{Message: message, Text: strings.Join(ch.Messages, "\n")}
+			case ChallengeTestError:
+				tc.Error = &junitResult{Message: ch.Error, Text: strings.Join(ch.Messages, "\n")}
+			case ChallengeTestSkipped:
+				tc.Skipped = &junitResult{}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		if track.CleanupError != "" {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "cleanup",
+				Classname: name,
+				Error:     &junitResult{Message: track.CleanupError},
+			})
+		}
+
+		for _, tc := range suite.Cases {
+			suite.Tests++
+			switch {
+			case tc.Failure != nil:
+				suite.Failures++
+			case tc.Error != nil:
+				suite.Errors++
+			case tc.Skipped != nil:
+				suite.Skipped++
+			}
+		}
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Errors += suite.Errors
+		root.Skipped += suite.Skipped
+		root.Suites = append(root.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitTime formats a duration in seconds, as expected by JUnit XML.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
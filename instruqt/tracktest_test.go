@@ -0,0 +1,174 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTestedTrack sets up the queries returning a track with two challenges.
+func mockTestedTrack(mockClient *MockGraphQLClient) {
+	mockClient.On("Query", mock.Anything, &trackQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*trackQuery)
+		q.Track = Track{Id: "track-123", Slug: "cilium-101"}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		q.Challenges = []Challenge{
+			{Id: "ch-2", Slug: "observe", Index: 1},
+			{Id: "ch-1", Slug: "install", Index: 0},
+		}
+	}).Return(nil)
+}
+
+func testLauncher(ctx context.Context, track Track) (TrackSession, error) {
+	return TrackSession{UserId: "user-123", SandboxId: "sandbox-123"}, nil
+}
+
+func TestTrackTester_Run(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockTestedTrack(mockClient)
+
+	mockClient.On("Mutate", mock.Anything, &startChallengeMutation{}, mock.Anything).Return(nil)
+	mockClient.On("Mutate", mock.Anything, &solveChallengeMutation{}, mock.Anything).Return(nil)
+	mockClient.On("Mutate", mock.Anything, &checkChallengeMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*checkChallengeMutation)
+		m.CheckChallenge = Challenge{Id: "ch-1", Status: "started", Attempts: []ChallengeAttempt{{Message: "cilium is not ready"}}}
+	}).Return(nil).Once()
+	mockClient.On("Mutate", mock.Anything, &stopSandboxMutation{}, mock.Anything).Return(nil).Once()
+
+	report, err := NewTrackTester(client, testLauncher).Run("track-123")
+
+	require.NoError(t, err)
+	require.Len(t, report.Tracks, 1)
+	track := report.Tracks[0]
+	assert.Equal(t, "cilium-101", track.TrackSlug)
+	assert.Equal(t, TrackSession{UserId: "user-123", SandboxId: "sandbox-123"}, track.Session)
+	require.Len(t, track.Challenges, 2)
+	assert.Equal(t, "install", track.Challenges[0].ChallengeSlug)
+	assert.Equal(t, ChallengeTestFailed, track.Challenges[0].Status)
+	assert.Equal(t, []string{"cilium is not ready"}, track.Challenges[0].Messages)
+	assert.Equal(t, ChallengeTestSkipped, track.Challenges[1].Status)
+	assert.False(t, report.Passed())
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNumberOfCalls(t, "Mutate", 4)
+}
+
+func TestTrackTester_ChallengeTimeout(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockTestedTrack(mockClient)
+
+	mockClient.On("Mutate", mock.Anything, &startChallengeMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.DeadlineExceeded)
+	mockClient.On("Mutate", mock.Anything, &stopSandboxMutation{}, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, args.Get(0).(context.Context).Err(), "Expected the sandbox to be stopped with a live context")
+	}).Return(nil).Once()
+
+	tester := NewTrackTester(client, testLauncher, WithChallengeTimeout(10*time.Millisecond))
+	report, err := tester.Run("track-123")
+
+	require.NoError(t, err)
+	challenge := report.Tracks[0].Challenges[0]
+	assert.Equal(t, ChallengeTestError, challenge.Status)
+	assert.Contains(t, challenge.Error, "timed out after 10ms")
+	mockClient.AssertExpectations(t)
+}
+
+func TestTrackTester_LaunchError(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+	mockTestedTrack(mockClient)
+
+	mockClient.On("Mutate", mock.Anything, &stopSandboxMutation{}, mock.Anything).Return(errors.New("sandbox not found")).Once()
+
+	launch := func(ctx context.Context, track Track) (TrackSession, error) {
+		return TrackSession{SandboxId: "sandbox-123"}, errors.New("sandbox failed to start")
+	}
+	report, err := NewTrackTester(client, launch).Run("track-123")
+
+	require.NoError(t, err)
+	track := report.Tracks[0]
+	assert.Equal(t, "failed to launch track: sandbox failed to start", track.Error)
+	assert.Contains(t, track.CleanupError, "sandbox not found")
+	for _, ch := range track.Challenges {
+		assert.Equal(t, ChallengeTestSkipped, ch.Status)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestTrackTestReport_Write(t *testing.T) {
+	report := &TrackTestReport{
+		Duration: 3 * time.Second,
+		Tracks: []TrackTestResult{{
+			TrackId:   "track-123",
+			TrackSlug: "cilium-101",
+			Session:   TrackSession{UserId: "user-123", SandboxId: "sandbox-123"},
+			Duration:  3 * time.Second,
+			Challenges: []ChallengeTestResult{
+				{ChallengeSlug: "install", Status: ChallengeTestPassed, Duration: time.Second},
+				{ChallengeSlug: "observe", Status: ChallengeTestFailed, Duration: 1500 * time.Millisecond, Messages: []string{"no flows", "hubble is not running"}},
+				{ChallengeSlug: "cleanup-policies", Status: ChallengeTestSkipped},
+			},
+		}, {
+			TrackId: "track-456",
+			Error:   "failed to get track: track not found",
+		}},
+	}
+
+	var junit bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&junit))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(junit.Bytes(), &suites))
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Equal(t, 1, suites.Skipped)
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "cilium-101", suites.Suites[0].Name)
+	assert.Equal(t, "3.000", suites.Suites[0].Time)
+	assert.Equal(t, "1.500", suites.Suites[0].Cases[1].Time)
+	require.NotNil(t, suites.Suites[0].Cases[1].Failure)
+	assert.Equal(t, "hubble is not running", suites.Suites[0].Cases[1].Failure.Message)
+	assert.Equal(t, "no flows\nhubble is not running", suites.Suites[0].Cases[1].Failure.Text)
+	assert.Equal(t, "track-456", suites.Suites[1].Name)
+	assert.Equal(t, "launch", suites.Suites[1].Cases[0].Name)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out))
+
+	var decoded TrackTestReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, report.Tracks, decoded.Tracks)
+}
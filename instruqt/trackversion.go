@@ -0,0 +1,388 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrackVersion is a version of the content of a track, as recorded by a
+// TrackVersionStore.
+type TrackVersion struct {
+	TrackId           string            `json:"track_id"`                     // The unique identifier of the track.
+	TrackSlug         string            `json:"track_slug"`                   // The slug of the track.
+	Version           int               `json:"version"`                      // The number of the version, starting at 1.
+	Hash              string            `json:"hash"`                         // The hash of the content of the track and its challenges.
+	ChallengeHashes   map[string]string `json:"challenge_hashes"`             // The hash of the content of each challenge, by challenge ID.
+	ChangedChallenges []string          `json:"changed_challenges,omitempty"` // The IDs of the challenges added, removed or changed since the previous version.
+	Since             time.Time         `json:"since"`                        // The estimated time of the change, see RecordTrack.
+	ObservedAt        time.Time         `json:"observed_at"`                  // The time the version was first recorded.
+}
+
+// TrackVersionStore records the versions of tracks by hashing their content,
+// including challenge assignments, each time they are polled. The Instruqt
+// API exposes no version history of tracks, only the time of their last
+// update, so this is how changes between releases of a track can be told
+// apart. A store can be saved to a JSON file and loaded back between polls.
+//
+// A TrackVersionStore is safe for concurrent use.
+type TrackVersionStore struct {
+	mu       sync.RWMutex
+	versions map[string][]TrackVersion // By track ID, oldest first.
+}
+
+// NewTrackVersionStore creates an empty version store.
+func NewTrackVersionStore() *TrackVersionStore {
+	return &TrackVersionStore{versions: make(map[string][]TrackVersion)}
+}
+
+// LoadTrackVersionStore loads a version store saved with Save. A missing
+// file gives an empty store.
+func LoadTrackVersionStore(path string) (*TrackVersionStore, error) {
+	s := NewTrackVersionStore()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.versions); err != nil {
+		return nil, fmt.Errorf("failed to decode track versions: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store to a JSON file.
+func (s *TrackVersionStore) Save(path string) error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.versions, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// RecordTrack records the current content of a track, with the assignments
+// of its challenges, if it differs from its latest version.
+//
+// The Since time of a new version is the Last_Update time of the track if it
+// falls between the previous poll and now, and the time of the poll
+// otherwise.
+//
+// Parameters:
+//   - track: The track, with its challenges and their assignments.
+//   - at: The time of the poll.
+//
+// Returns:
+//   - TrackVersion: The latest version of the track.
+//   - bool: Whether a new version was recorded.
+func (s *TrackVersionStore) RecordTrack(track Track, at time.Time) (TrackVersion, bool) {
+	hash, challengeHashes := hashTrackContent(track)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[track.Id]
+	version := TrackVersion{
+		TrackId:         track.Id,
+		TrackSlug:       track.Slug,
+		Version:         1,
+		Hash:            hash,
+		ChallengeHashes: challengeHashes,
+		Since:           at,
+		ObservedAt:      at,
+	}
+
+	var previous time.Time
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if latest.Hash == hash {
+			return latest, false
+		}
+		version.Version = latest.Version + 1
+		version.ChangedChallenges = changedChallenges(latest.ChallengeHashes, challengeHashes)
+		previous = latest.ObservedAt
+	}
+	if !track.Last_Update.IsZero() && track.Last_Update.After(previous) && !track.Last_Update.After(at) {
+		version.Since = track.Last_Update
+	}
+
+	s.versions[track.Id] = append(versions, version)
+	return version, true
+}
+
+// Versions returns the timeline of the versions of a track, oldest first.
+func (s *TrackVersionStore) Versions(trackId string) []TrackVersion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.versions[trackId])
+}
+
+// VersionAt returns the version of a track that was live at a given time.
+//
+// Returns:
+//   - TrackVersion: The version live at the given time.
+//   - bool: Whether a version was recorded by then.
+func (s *TrackVersionStore) VersionAt(trackId string, at time.Time) (TrackVersion, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.versions[trackId]
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].Since.After(at) })
+	if i == 0 {
+		return TrackVersion{}, false
+	}
+	return versions[i-1], true
+}
+
+// VersionCompletion holds the plays of a track started while a version of
+// it was live.
+type VersionCompletion struct {
+	Version           TrackVersion `json:"version"`            // The version of the track.
+	Plays             int          `json:"plays"`              // The number of plays started on the version.
+	Completed         int          `json:"completed"`          // The number of plays that completed the track.
+	AverageCompletion float64      `json:"average_completion"` // The average CompletionPercent of the plays.
+}
+
+// CompletionByVersion groups the plays of a track by the version live when
+// they started, to correlate content changes with drops in completion.
+// Plays of other tracks and plays started before the first recorded version
+// are ignored.
+//
+// Parameters:
+//   - trackId: The unique identifier of the track.
+//   - plays: The play reports, such as those returned by GetPlays or AllPlays.
+//
+// Returns:
+//   - []VersionCompletion: The completion of each version, oldest first.
+func (s *TrackVersionStore) CompletionByVersion(trackId string, plays []PlayReport) []VersionCompletion {
+	versions := s.Versions(trackId)
+	completions := make([]VersionCompletion, len(versions))
+	for i, v := range versions {
+		completions[i].Version = v
+	}
+
+	for _, play := range plays {
+		if play.Track.Id != trackId {
+			continue
+		}
+		i := sort.Search(len(versions), func(i int) bool { return versions[i].Since.After(play.StartedAt) })
+		if i == 0 {
+			continue
+		}
+		c := &completions[i-1]
+		c.Plays++
+		c.AverageCompletion += play.CompletionPercent
+		if play.CompletionPercent >= 100 {
+			c.Completed++
+		}
+	}
+
+	for i := range completions {
+		if completions[i].Plays > 0 {
+			completions[i].AverageCompletion /= float64(completions[i].Plays)
+		}
+	}
+	return completions
+}
+
+// RecordTrackVersions polls all tracks of the team, with the assignments of
+// their challenges, and records their versions in a store. A track is only
+// recorded if all of its content could be retrieved, as a partial track
+// would get a new version.
+//
+// Parameters:
+//   - store: The store recording the versions.
+//   - opts: Optional settings, such as WithConcurrency or WithErrorPolicy.
+//
+// Returns:
+//   - []TrackVersion: The new versions recorded. With ErrorPolicyCollectAll,
+//     they are returned along with the error, leaving out the tracks that
+//     could not be retrieved completely.
+//   - error: Any error encountered while polling the tracks.
+func (c *Client) RecordTrackVersions(store *TrackVersionStore, opts ...Option) ([]TrackVersion, error) {
+	return c.RecordTrackVersionsCtx(c.context(), store, opts...)
+}
+
+// RecordTrackVersionsCtx is like RecordTrackVersions but uses the given context instead of the client's Context.
+func (c *Client) RecordTrackVersionsCtx(ctx context.Context, store *TrackVersionStore, opts ...Option) (recorded []TrackVersion, err error) {
+	ctx, end := c.startOperation(ctx, "RecordTrackVersions")
+	defer func() { end(err) }()
+
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// The error of each track, if some of its content could not be retrieved.
+	var (
+		tracks    []Track
+		trackErrs []error
+	)
+	if options.errorPolicy == ErrorPolicyCollectAll {
+		enrichment, err := c.GetTracksEnrichmentCtx(ctx, append([]Option{WithChallenges()}, opts...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks: %w", err)
+		}
+		tracks = enrichment.Tracks
+		trackErrs = make([]error, len(tracks))
+		for i, t := range tracks {
+			trackErrs[i] = enrichment.Errors[t.Id]
+		}
+	} else {
+		tracks, err = c.GetTracksCtx(ctx, append([]Option{WithChallenges()}, opts...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks: %w", err)
+		}
+		trackErrs = make([]error, len(tracks))
+	}
+
+	var (
+		challenges []*Challenge
+		owners     []int // The index of the track of each challenge.
+	)
+	for i := range tracks {
+		if trackErrs[i] != nil {
+			continue
+		}
+		for j := range tracks[i].Challenges {
+			challenges = append(challenges, &tracks[i].Challenges[j])
+			owners = append(owners, i)
+		}
+	}
+	challengeErrs := make([]error, len(challenges))
+	err = fanOut(ctx, len(challenges), options.concurrency, options.errorPolicy, func(ctx context.Context, i int) error {
+		assignment, err := c.GetChallengeWithAssignmentCtx(ctx, challenges[i].Id)
+		if err != nil {
+			challengeErrs[i] = fmt.Errorf("failed to get assignment for challenge %s: %w", challenges[i].Id, err)
+			return challengeErrs[i]
+		}
+		challenges[i].Assignment = assignment.Assignment
+		return nil
+	})
+	if err != nil && options.errorPolicy == ErrorPolicyFailFast {
+		return nil, err
+	}
+	for i, err := range challengeErrs {
+		if err != nil && trackErrs[owners[i]] == nil {
+			trackErrs[owners[i]] = err
+		}
+	}
+
+	now := time.Now().UTC()
+	for i, t := range tracks {
+		if trackErrs[i] != nil {
+			// A track recorded without some of its content would get a new version.
+			continue
+		}
+		if version, ok := store.RecordTrack(t, now); ok {
+			recorded = append(recorded, version)
+		}
+	}
+	return recorded, errors.Join(trackErrs...)
+}
+
+// trackContent is the content of a track hashed by hashTrackContent.
+type trackContent struct {
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Teaser      string   `json:"teaser"`
+	Description string   `json:"description"`
+	Level       string   `json:"level"`
+	Icon        string   `json:"icon"`
+	Tags        []string `json:"tags"`
+}
+
+// challengeContent is the content of a challenge hashed by hashTrackContent.
+type challengeContent struct {
+	Slug       string `json:"slug"`
+	Title      string `json:"title"`
+	Teaser     string `json:"teaser"`
+	Type       string `json:"type"`
+	Index      int    `json:"index"`
+	Assignment string `json:"assignment"`
+}
+
+// hashTrackContent hashes the content of a track and of each of its
+// challenges. Statistics, timestamps and user progress are left out.
+func hashTrackContent(track Track) (string, map[string]string) {
+	content := trackContent{
+		Slug:        track.Slug,
+		Title:       track.Title,
+		Teaser:      track.Teaser,
+		Description: track.Description,
+		Level:       track.Level,
+		Icon:        track.Icon,
+	}
+	for _, tag := range track.TrackTags {
+		content.Tags = append(content.Tags, tag.Value)
+	}
+
+	// Marshaling strings and ints cannot fail. The newline keeps the hashes
+	// of recorded versions unchanged.
+	data, _ := json.Marshal(content)
+	h := sha256.New()
+	h.Write(append(data, '\n'))
+
+	challengeHashes := make(map[string]string, len(track.Challenges))
+	challenges := slices.Clone(track.Challenges)
+	slices.SortFunc(challenges, func(a, b Challenge) int { return a.Index - b.Index })
+	for _, ch := range challenges {
+		data, _ := json.Marshal(challengeContent{
+			Slug:       ch.Slug,
+			Title:      ch.Title,
+			Teaser:     ch.Teaser,
+			Type:       ch.Type,
+			Index:      ch.Index,
+			Assignment: ch.Assignment,
+		})
+		sum := sha256.Sum256(data)
+		challengeHashes[ch.Id] = hex.EncodeToString(sum[:])
+		fmt.Fprintf(h, "%s:%s\n", ch.Id, challengeHashes[ch.Id])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), challengeHashes
+}
+
+// changedChallenges returns the sorted IDs of the challenges whose hashes
+// differ between two versions.
+func changedChallenges(old, new map[string]string) []string {
+	var ids []string
+	for id, hash := range new {
+		if old[id] != hash {
+			ids = append(ids, id)
+		}
+	}
+	for id := range old {
+		if _, ok := new[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
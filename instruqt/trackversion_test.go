@@ -0,0 +1,148 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionedTrack() Track {
+	return Track{
+		Id:    "track-1",
+		Slug:  "getting-started",
+		Title: "Getting Started",
+		Challenges: []Challenge{
+			{Id: "ch-1", Slug: "intro", Index: 0, Assignment: "Welcome"},
+			{Id: "ch-2", Slug: "install", Index: 1, Assignment: "Run `cilium install`."},
+		},
+	}
+}
+
+func TestTrackVersionStore_RecordTrack(t *testing.T) {
+	poll := func(h int) time.Time { return time.Date(2024, 6, 1, h, 0, 0, 0, time.UTC) }
+	store := NewTrackVersionStore()
+	track := testVersionedTrack()
+
+	v1, ok := store.RecordTrack(track, poll(1))
+	require.True(t, ok)
+	assert.Equal(t, 1, v1.Version)
+	assert.Equal(t, poll(1), v1.Since)
+
+	// Statistics and progress are not part of the content.
+	track.Statistics.Average_review_score = 5
+	track.Challenges[0].Status = "completed"
+	_, ok = store.RecordTrack(track, poll(2))
+	assert.False(t, ok)
+
+	track.Challenges[1].Assignment = "Run `cilium install --wait`."
+	track.Last_Update = poll(2).Add(30 * time.Minute)
+	v2, ok := store.RecordTrack(track, poll(3))
+	require.True(t, ok)
+	assert.Equal(t, 2, v2.Version)
+	assert.Equal(t, []string{"ch-2"}, v2.ChangedChallenges)
+	assert.Equal(t, track.Last_Update, v2.Since)
+	assert.Equal(t, poll(3), v2.ObservedAt)
+
+	version, ok := store.VersionAt("track-1", poll(2))
+	require.True(t, ok)
+	assert.Equal(t, 1, version.Version)
+	_, ok = store.VersionAt("track-1", poll(0))
+	assert.False(t, ok)
+}
+
+func TestTrackVersionStore_CompletionByVersion(t *testing.T) {
+	poll := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	store := NewTrackVersionStore()
+	track := testVersionedTrack()
+	store.RecordTrack(track, poll(1))
+	track.Challenges = track.Challenges[:1]
+	store.RecordTrack(track, poll(10))
+
+	play := func(trackID string, started time.Time, completion float64) PlayReport {
+		p := PlayReport{StartedAt: started, CompletionPercent: completion}
+		p.Track.Id = trackID
+		return p
+	}
+	completions := store.CompletionByVersion("track-1", []PlayReport{
+		play("track-1", poll(0), 100), // Before the first version.
+		play("track-1", poll(2), 100),
+		play("track-1", poll(3), 50),
+		play("track-1", poll(11), 20),
+		play("track-2", poll(11), 100),
+	})
+
+	require.Len(t, completions, 2)
+	assert.Equal(t, 2, completions[0].Plays)
+	assert.Equal(t, 1, completions[0].Completed)
+	assert.Equal(t, 75.0, completions[0].AverageCompletion)
+	assert.Equal(t, 1, completions[1].Plays)
+	assert.Equal(t, 20.0, completions[1].AverageCompletion)
+	assert.Equal(t, []string{"ch-2"}, completions[1].Version.ChangedChallenges)
+}
+
+func TestTrackVersionStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+
+	store, err := LoadTrackVersionStore(path)
+	require.NoError(t, err)
+	store.RecordTrack(testVersionedTrack(), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, store.Save(path))
+
+	loaded, err := LoadTrackVersionStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, store.Versions("track-1"), loaded.Versions("track-1"))
+	_, ok := loaded.RecordTrack(testVersionedTrack(), time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
+
+func TestRecordTrackVersions_CollectAll(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+	}
+
+	mockClient.On("Query", mock.Anything, &tracksQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*tracksQuery)
+		q.Tracks = []Track{{Id: "track-1"}, {Id: "track-2"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, &challengesQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*challengesQuery)
+		id := args.Get(2).(map[string]interface{})["trackId"].(graphql.String)
+		q.Challenges = []Challenge{{Id: string(id) + "-ch-1"}}
+	}).Return(nil)
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.MatchedBy(func(vars map[string]interface{}) bool {
+		return vars["challengeId"] == graphql.String("track-2-ch-1")
+	})).Return(errors.New("graphql error"))
+	mockClient.On("Query", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal([]byte(`{"Challenge":{"assignment":"Welcome"}}`), args.Get(1)))
+	}).Return(nil)
+
+	store := NewTrackVersionStore()
+	recorded, err := client.RecordTrackVersions(store, WithErrorPolicy(ErrorPolicyCollectAll))
+
+	assert.ErrorContains(t, err, "failed to get assignment for challenge track-2-ch-1")
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "track-1", recorded[0].TrackId)
+	assert.Empty(t, store.Versions("track-2"), "Expected a track with a missing assignment not to be recorded")
+}
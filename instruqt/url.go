@@ -0,0 +1,274 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultPlayURL is the base URL of the Instruqt play site, used to build
+// embed, invite and launch URLs.
+const DefaultPlayURL = "https://play.instruqt.com"
+
+// Names of the query parameters set by the URL builder.
+const (
+	embedTokenParam    = "token"   // The embed token of the track.
+	encryptedPIIParam  = "pii_tpg" // The user's PII, encrypted with the team's TPG public key.
+	oneTimeTokenParam  = "ott"     // A one-time play token.
+	customParamPrefix  = "icp_"    // The prefix of custom parameters, reported in play reports.
+	maxParamNameLength = 64        // The maximum length of a parameter name, prefix included.
+	maxParamValueLen   = 512       // The maximum length of a parameter value.
+)
+
+// paramNamePattern matches the allowed names of query parameters.
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// URLOption defines a functional option for the URL builder methods, such
+// as EmbedURL, InviteURL and LaunchURL.
+type URLOption func(*urlOptions)
+
+// urlOptions holds the settings of the URL builder methods.
+type urlOptions struct {
+	playURL      string
+	pii          *OneTimeTokenUserDetailsInput
+	params       [][2]string
+	oneTimeToken bool
+	tokenOpts    []Option
+}
+
+// UTM holds the UTM fields used to attribute a visit to a campaign. Empty
+// fields are left out of the URL.
+type UTM struct {
+	Source   string // The utm_source parameter.
+	Medium   string // The utm_medium parameter.
+	Campaign string // The utm_campaign parameter.
+	Term     string // The utm_term parameter.
+	Content  string // The utm_content parameter.
+}
+
+// WithPlayURL sets the base URL of the play site, such as a custom domain.
+// Usage: EmbedURL(track, WithPlayURL("https://labs.example.com"))
+func WithPlayURL(playURL string) URLOption {
+	return func(o *urlOptions) {
+		o.playURL = playURL
+	}
+}
+
+// WithEncryptedPII passes the user's details to Instruqt, encrypted with
+// EncryptUserPII so that the user does not have to fill them in.
+// Usage: EmbedURL(track, WithEncryptedPII("Ada", "Lovelace", "ada@example.com"))
+func WithEncryptedPII(firstName, lastName, email string) URLOption {
+	return func(o *urlOptions) {
+		o.pii = &OneTimeTokenUserDetailsInput{
+			FirstName: firstName,
+			LastName:  lastName,
+			Email:     email,
+		}
+	}
+}
+
+// WithParam adds a query parameter to the URL. Its name may only contain
+// letters, digits and underscores, and may not be one of the parameters set
+// by other options.
+// Usage: LaunchURL(track, WithParam("lang", "fr"))
+func WithParam(name, value string) URLOption {
+	return func(o *urlOptions) {
+		o.params = append(o.params, [2]string{name, value})
+	}
+}
+
+// WithCustomParameter adds a custom parameter to the URL, prefixed with
+// "icp_". Custom parameters are reported in play reports, and can be used to
+// filter them with WithCustomParameterFilter.
+// Usage: EmbedURL(track, WithCustomParameter("campaign_id", "kubecon"))
+func WithCustomParameter(name, value string) URLOption {
+	return WithParam(customParamPrefix+name, value)
+}
+
+// WithUTM adds the non-empty UTM fields to the URL.
+// Usage: InviteURL(inviteID, WithUTM(UTM{Source: "newsletter", Campaign: "launch"}))
+func WithUTM(utm UTM) URLOption {
+	return func(o *urlOptions) {
+		for _, p := range [][2]string{
+			{"utm_source", utm.Source},
+			{"utm_medium", utm.Medium},
+			{"utm_campaign", utm.Campaign},
+			{"utm_term", utm.Term},
+			{"utm_content", utm.Content},
+		} {
+			if p[1] != "" {
+				o.params = append(o.params, p)
+			}
+		}
+	}
+}
+
+// WithOneTimePlayToken adds a one-time play token to the URL, generated with
+// GenerateOneTimePlayToken and the given options, such as WithUserDetails.
+// It is not supported by InviteURL.
+// Usage: LaunchURL(track, WithOneTimePlayToken(WithUserDetails("Ada", "Lovelace", "ada@example.com")))
+func WithOneTimePlayToken(opts ...Option) URLOption {
+	return func(o *urlOptions) {
+		o.oneTimeToken = true
+		o.tokenOpts = opts
+	}
+}
+
+// EmbedURL builds the URL embedding a track in a web page, using its embed
+// token.
+//
+// Parameters:
+//   - track: The track to embed. Its Slug and Embed_Token must be set.
+//   - opts: Optional settings, such as WithEncryptedPII, WithCustomParameter or WithUTM.
+//
+// Returns:
+//   - string: The embed URL.
+//   - error: Any error encountered while building the URL.
+func (c *Client) EmbedURL(track Track, opts ...URLOption) (string, error) {
+	return c.EmbedURLCtx(c.context(), track, opts...)
+}
+
+// EmbedURLCtx is like EmbedURL but uses the given context instead of the client's Context.
+func (c *Client) EmbedURLCtx(ctx context.Context, track Track, opts ...URLOption) (string, error) {
+	if track.Slug == "" || track.Embed_Token == "" {
+		return "", fmt.Errorf("%w: the slug and embed token of the track are required", ErrValidation)
+	}
+	return c.buildURL(ctx, []string{"embed", c.TeamSlug, "tracks", track.Slug}, track.Id,
+		[][2]string{{embedTokenParam, track.Embed_Token}}, opts)
+}
+
+// InviteURL builds the URL through which users claim a track invite.
+//
+// Parameters:
+//   - inviteId: The unique identifier of the invite.
+//   - opts: Optional settings, such as WithEncryptedPII, WithCustomParameter or WithUTM.
+//
+// Returns:
+//   - string: The invite URL.
+//   - error: Any error encountered while building the URL.
+func (c *Client) InviteURL(inviteId string, opts ...URLOption) (string, error) {
+	return c.InviteURLCtx(c.context(), inviteId, opts...)
+}
+
+// InviteURLCtx is like InviteURL but uses the given context instead of the client's Context.
+func (c *Client) InviteURLCtx(ctx context.Context, inviteId string, opts ...URLOption) (string, error) {
+	if inviteId == "" {
+		return "", fmt.Errorf("%w: the invite ID is required", ErrValidation)
+	}
+	return c.buildURL(ctx, []string{c.TeamSlug, "invite", inviteId}, "", nil, opts)
+}
+
+// LaunchURL builds the URL starting a track directly on the play site.
+//
+// Parameters:
+//   - track: The track to start. Its Slug must be set, as well as its Id
+//     with WithOneTimePlayToken.
+//   - opts: Optional settings, such as WithOneTimePlayToken, WithCustomParameter or WithUTM.
+//
+// Returns:
+//   - string: The launch URL.
+//   - error: Any error encountered while building the URL.
+func (c *Client) LaunchURL(track Track, opts ...URLOption) (string, error) {
+	return c.LaunchURLCtx(c.context(), track, opts...)
+}
+
+// LaunchURLCtx is like LaunchURL but uses the given context instead of the client's Context.
+func (c *Client) LaunchURLCtx(ctx context.Context, track Track, opts ...URLOption) (string, error) {
+	if track.Slug == "" {
+		return "", fmt.Errorf("%w: the slug of the track is required", ErrValidation)
+	}
+	return c.buildURL(ctx, []string{c.TeamSlug, "tracks", track.Slug}, track.Id, nil, opts)
+}
+
+// buildURL validates the parameters set by opts, generates the tokens and
+// encrypted PII they require, and joins everything into a URL. fixed holds
+// the parameters set by the method, such as EmbedURL. trackId is used for
+// one-time play tokens, which are refused when it is empty.
+func (c *Client) buildURL(ctx context.Context, path []string, trackId string, fixed [][2]string, opts []URLOption) (string, error) {
+	options := &urlOptions{playURL: DefaultPlayURL}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Validate every parameter before making any call.
+	for _, p := range options.params {
+		if err := validateURLParam(p[0], p[1]); err != nil {
+			return "", err
+		}
+	}
+	if options.oneTimeToken && trackId == "" {
+		return "", fmt.Errorf("%w: one-time play tokens require a track ID", ErrValidation)
+	}
+
+	base, err := url.Parse(options.playURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid play URL: %v", ErrValidation, err)
+	}
+	for _, segment := range path {
+		base = base.JoinPath(segment)
+	}
+
+	query := url.Values{}
+	for _, p := range fixed {
+		query.Set(p[0], p[1])
+	}
+	for _, p := range options.params {
+		query.Set(p[0], p[1])
+	}
+
+	if options.pii != nil {
+		pii, err := c.EncryptUserPIICtx(ctx, options.pii.FirstName, options.pii.LastName, options.pii.Email)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt PII: %w", err)
+		}
+		query.Set(encryptedPIIParam, pii)
+	}
+
+	if options.oneTimeToken {
+		token, err := c.GenerateOneTimePlayTokenCtx(ctx, trackId, options.tokenOpts...)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate one-time play token: %w", err)
+		}
+		query.Set(oneTimeTokenParam, token)
+	}
+
+	base.RawQuery = query.Encode()
+	return base.String(), nil
+}
+
+// validateURLParam checks the name and value of a parameter set with
+// WithParam, WithCustomParameter or WithUTM.
+func validateURLParam(name, value string) error {
+	switch {
+	case !paramNamePattern.MatchString(name):
+		return fmt.Errorf("%w: invalid parameter name %q: only letters, digits and underscores are allowed", ErrValidation, name)
+	case name == customParamPrefix:
+		return fmt.Errorf("%w: custom parameter names cannot be empty", ErrValidation)
+	case len(name) > maxParamNameLength:
+		return fmt.Errorf("%w: parameter name %q is longer than %d characters", ErrValidation, name, maxParamNameLength)
+	case len(value) > maxParamValueLen:
+		return fmt.Errorf("%w: value of parameter %q is longer than %d characters", ErrValidation, name, maxParamValueLen)
+	}
+
+	switch strings.ToLower(name) {
+	case embedTokenParam, encryptedPIIParam, oneTimeTokenParam:
+		return fmt.Errorf("%w: parameter %q is reserved", ErrValidation, name)
+	}
+	return nil
+}
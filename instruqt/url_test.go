@@ -0,0 +1,125 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"strings"
+	"testing"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedURL(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: publicKeyBytes})
+
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		TeamSlug:      "isovalent",
+	}
+	mockClient.On("Query", mock.Anything, &teamQuery{}, mock.Anything).Run(func(args mock.Arguments) {
+		q := args.Get(1).(*teamQuery)
+		q.Team.TPGPublicKey = graphql.String(publicKeyPEM)
+	}).Return(nil)
+
+	track := Track{Id: "track-123", Slug: "getting-started", Embed_Token: "em_abc"}
+	embedURL, err := client.EmbedURL(track,
+		WithEncryptedPII("Ada", "Lovelace", "ada@example.com"),
+		WithCustomParameter("campaign_id", "kubecon"),
+		WithUTM(UTM{Source: "newsletter", Campaign: "launch"}),
+	)
+
+	require.NoError(t, err)
+	u, err := url.Parse(embedURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://play.instruqt.com/embed/isovalent/tracks/getting-started", u.Scheme+"://"+u.Host+u.Path)
+	query := u.Query()
+	assert.Equal(t, "em_abc", query.Get("token"))
+	assert.Equal(t, "kubecon", query.Get("icp_campaign_id"))
+	assert.Equal(t, "newsletter", query.Get("utm_source"))
+	assert.Equal(t, "launch", query.Get("utm_campaign"))
+	assert.False(t, query.Has("utm_medium"))
+	assert.NotEmpty(t, query.Get("pii_tpg"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestLaunchURL_WithOneTimePlayToken(t *testing.T) {
+	mockClient := new(MockGraphQLClient)
+	client := &Client{
+		GraphQLClient: mockClient,
+		TeamSlug:      "isovalent",
+	}
+	mockClient.On("Mutate", mock.Anything, mock.Anything, map[string]any{"trackID": graphql.String("track-123")}).Run(func(args mock.Arguments) {
+		m := args.Get(1).(*struct {
+			GenerateOneTimePlayToken string `graphql:"generateOneTimePlayToken(trackID: $trackID)"`
+		})
+		m.GenerateOneTimePlayToken = "ott_123"
+	}).Return(nil)
+
+	launchURL, err := client.LaunchURL(Track{Id: "track-123", Slug: "getting-started"},
+		WithOneTimePlayToken(),
+		WithPlayURL("https://labs.example.com/"),
+	)
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://labs.example.com/isovalent/tracks/getting-started?ott=ott_123", launchURL)
+	mockClient.AssertExpectations(t)
+}
+
+func TestInviteURL(t *testing.T) {
+	client := &Client{TeamSlug: "isovalent"}
+
+	inviteURL, err := client.InviteURL("invite-123", WithParam("lang", "fr"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://play.instruqt.com/isovalent/invite/invite-123?lang=fr", inviteURL)
+
+	_, err = client.InviteURL("invite-123", WithOneTimePlayToken())
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestURL_Validation(t *testing.T) {
+	client := &Client{TeamSlug: "isovalent"}
+	track := Track{Id: "track-123", Slug: "getting-started"}
+
+	for name, opt := range map[string]URLOption{
+		"invalid name":     WithParam("campaign id", "x"),
+		"empty custom":     WithCustomParameter("", "x"),
+		"reserved name":    WithParam("token", "x"),
+		"reserved ott":     WithParam("OTT", "x"),
+		"long name":        WithCustomParameter(strings.Repeat("a", 64), "x"),
+		"long value":       WithCustomParameter("campaign", strings.Repeat("a", 513)),
+		"invalid play url": WithPlayURL("://"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.LaunchURL(track, opt)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+
+	_, err := client.EmbedURL(track)
+	assert.ErrorIs(t, err, ErrValidation, "Expected the embed token to be required")
+}
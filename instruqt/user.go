@@ -15,7 +15,8 @@
 package instruqt
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"strings"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -69,42 +70,57 @@ type UserInfo struct {
 //   - UserInfo: The user's information including first name, last name, and email.
 //   - error: Any error encountered while retrieving the user information.
 func (c *Client) GetUserInfo(userId string) (u UserInfo, err error) {
+	return c.GetUserInfoCtx(c.context(), userId)
+}
+
+// GetUserInfoCtx is like GetUserInfo but uses the given context instead of the client's Context.
+func (c *Client) GetUserInfoCtx(ctx context.Context, userId string) (u UserInfo, err error) {
 	var q userInfoQuery
 	variables := map[string]interface{}{
 		"teamSlug": graphql.String(c.TeamSlug),
 		"userID":   graphql.String(userId),
 	}
-	if err := c.GraphQLClient.Query(c.Context, &q, variables); err != nil {
-		return u, fmt.Errorf("[GetUserInfo] Failed to retrieve user info: %v", err)
+	if err := c.query(ctx, "GetUserInfo", &q, variables); err != nil {
+		return u, err
 	}
 
-	u = UserInfo{}
+	u, source := userInfo(q.User)
+	if source != "" {
+		c.logger().InfoContext(ctx, "Found user info from instruqt user "+source,
+			slog.String("operation", "GetUserInfo"),
+			slog.String("user_id", userId),
+		)
+	}
 
-	if q.User.Details != nil {
-		u.Consent = bool(q.User.Details.Consent)
+	return u, nil
+}
 
-		if q.User.Details.Email != "" {
-			c.InfoLogger.Printf("[Instruqt][GetUserInfo][%s] Found user info from instruqt user details", userId)
-			u.FirstName = string(q.User.Details.FirstName)
-			u.LastName = string(q.User.Details.LastName)
-			u.Email = string(q.User.Details.Email)
-			return u, nil
+// userInfo simplifies a user into a UserInfo, preferring the team-specific
+// details over the profile. It also returns the source of the information,
+// "details" or "profile", or an empty string if neither had an email.
+func userInfo(user User) (u UserInfo, source string) {
+	if user.Details != nil {
+		u.Consent = bool(user.Details.Consent)
+
+		if user.Details.Email != "" {
+			u.FirstName = string(user.Details.FirstName)
+			u.LastName = string(user.Details.LastName)
+			u.Email = string(user.Details.Email)
+			return u, "details"
 		}
 	}
 
-	if q.User.Profile != nil && q.User.Profile.Email != "" {
-		c.InfoLogger.Printf("[Instruqt][GetUserInfo][%s] Found user info from instruqt user profile", userId)
-		nameParts := strings.Fields(string(q.User.Profile.Display_Name))
-		u.FirstName = nameParts[0]
+	if user.Profile != nil && user.Profile.Email != "" {
+		nameParts := strings.Fields(string(user.Profile.Display_Name))
+		if len(nameParts) > 0 {
+			u.FirstName = nameParts[0]
+		}
 		if len(nameParts) > 1 {
 			u.LastName = strings.Join(nameParts[1:], " ")
-		} else {
-			u.LastName = ""
 		}
-		u.Email = string(q.User.Profile.Email)
-
-		return u, nil
+		u.Email = string(user.Profile.Email)
+		return u, "profile"
 	}
 
-	return u, nil
+	return u, ""
 }
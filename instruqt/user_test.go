@@ -110,6 +110,9 @@ func TestGetUserInfo_Error(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Equal(t, UserInfo{}, userInfo)
-	assert.Contains(t, err.Error(), "Failed to retrieve user info")
+	assert.Contains(t, err.Error(), "GetUserInfo: graphql error")
+	var transportErr *TransportError
+	assert.ErrorAs(t, err, &transportErr)
+	assert.Equal(t, "GetUserInfo", transportErr.Operation)
 	mockClient.AssertExpectations(t)
 }
@@ -0,0 +1,326 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/isovalent/instruqt-go/instruqt"
+)
+
+// scrubbed replaces sensitive values in recorded cassettes, such as the
+// fields reported by instruqt.IsPIIField.
+const scrubbed = "[SCRUBBED]"
+
+// ErrNoInteraction is returned when a cassette has no interaction matching
+// a replayed request.
+var ErrNoInteraction = errors.New("instruqttest: no recorded interaction")
+
+// Mode defines whether a Recorder records or replays interactions.
+type Mode int
+
+// Constants representing the modes of a Recorder.
+const (
+	ModeReplay Mode = iota // Serve requests from the cassette, without network access.
+	ModeRecord             // Forward requests to the API and record them in the cassette.
+)
+
+// Cassette is a recording of GraphQL interactions, as stored on disk.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded GraphQL request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a scrubbed GraphQL request.
+type RecordedRequest struct {
+	Method    string         `json:"method"`
+	URL       string         `json:"url"`
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// RecordedResponse is a scrubbed HTTP response.
+type RecordedResponse struct {
+	StatusCode  int             `json:"status_code"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// Recorder is an HTTP RoundTripper that records GraphQL interactions to a
+// cassette file, or replays them from it. Requests are matched on their
+// GraphQL document and variables. Bearer tokens and personal information
+// are scrubbed from recordings, and from requests before matching.
+//
+// Usage:
+//
+//	recorder, err := instruqttest.NewRecorder("testdata/tracks.json", instruqttest.ModeReplay)
+//	client := instruqt.NewClientWithOptions(token, teamSlug, instruqt.WithRoundTripper(recorder))
+//	defer recorder.Save()
+type Recorder struct {
+	Transport http.RoundTripper // The transport used in ModeRecord. Defaults to http.DefaultTransport.
+
+	path     string
+	mode     Mode
+	mu       sync.Mutex
+	cassette Cassette
+	replayed []bool
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In ModeReplay,
+// the cassette is loaded from disk and must exist.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	r.replayed = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file. It does
+// nothing in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// RoundTrip records or replays a GraphQL request, depending on the mode of
+// the Recorder.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	token := bearerToken(req)
+	recorded, err := recordRequest(req, body, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded, token)
+}
+
+// record forwards the request and records the interaction.
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest, token string) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        scrubBody(body, token),
+		},
+	})
+	return resp, nil
+}
+
+// replay serves the first interaction matching the request that has not
+// been replayed yet, or the last matching one if all have been.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !matches(interaction.Request, recorded) {
+			continue
+		}
+		match = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("%w in %s for query %q with variables %v", ErrNoInteraction, r.path, recorded.Query, recorded.Variables)
+	}
+	r.replayed[match] = true
+
+	resp := r.cassette.Interactions[match].Response
+	body := []byte(resp.Body)
+	var s string
+	if json.Unmarshal(resp.Body, &s) == nil {
+		// Bodies that were not JSON are stored as strings.
+		body = []byte(s)
+	}
+
+	header := http.Header{}
+	if resp.ContentType != "" {
+		header.Set("Content-Type", resp.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// matches reports whether a recorded request matches an incoming one.
+func matches(recorded RecordedRequest, req RecordedRequest) bool {
+	return recorded.Method == req.Method &&
+		recorded.Query == req.Query &&
+		reflect.DeepEqual(normalizeVariables(recorded.Variables), normalizeVariables(req.Variables))
+}
+
+// normalizeVariables treats absent and empty variables alike.
+func normalizeVariables(v map[string]any) map[string]any {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// recordRequest returns the scrubbed form of a GraphQL request.
+func recordRequest(req *http.Request, body []byte, token string) (RecordedRequest, error) {
+	recorded := RecordedRequest{Method: req.Method, URL: req.URL.String()}
+	if len(body) == 0 {
+		return recorded, nil
+	}
+
+	if token != "" {
+		body = bytes.ReplaceAll(body, []byte(token), []byte(scrubbed))
+	}
+	var gqlReq Request
+	if err := json.Unmarshal(body, &gqlReq); err != nil {
+		return recorded, fmt.Errorf("failed to decode GraphQL request: %w", err)
+	}
+	recorded.Query = strings.Join(strings.Fields(gqlReq.Query), " ")
+	if vars, ok := scrubValue(any(gqlReq.Variables), false).(map[string]any); ok {
+		recorded.Variables = vars
+	}
+	return recorded, nil
+}
+
+// scrubBody returns a JSON body with the token and personal information
+// scrubbed. Bodies that are not JSON are stored as JSON strings.
+func scrubBody(body []byte, token string) json.RawMessage {
+	if token != "" {
+		body = bytes.ReplaceAll(body, []byte(token), []byte(scrubbed))
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		b, _ := json.Marshal(string(body))
+		return b
+	}
+	b, err := json.Marshal(scrubValue(v, false))
+	if err != nil {
+		b, _ = json.Marshal(string(body))
+	}
+	return b
+}
+
+// scrubValue recursively scrubs the sensitive fields of a decoded JSON
+// value. All strings within a sensitive value are scrubbed, so that the
+// shape of the value is preserved.
+func scrubValue(v any, sensitive bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = scrubValue(value, sensitive || instruqt.IsPIIField(key))
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = scrubValue(value, sensitive)
+		}
+	case string:
+		if sensitive && v != "" {
+			return scrubbed
+		}
+	}
+	return v
+}
+
+// bearerToken returns the bearer token of the request, if any.
+func bearerToken(req *http.Request) string {
+	token, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return token
+}
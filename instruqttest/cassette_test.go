@@ -0,0 +1,111 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isovalent/instruqt-go/instruqt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	data := testDataset()
+	data.Token = "secret-token"
+	server := NewServer(data)
+	path := filepath.Join(t.TempDir(), "cassettes", "user.json")
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	require.NoError(t, err)
+	client := server.Client(instruqt.WithRoundTripper(recorder))
+
+	track, err := client.GetTrackById("track-1")
+	require.NoError(t, err)
+	info, err := client.GetUserInfo("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", info.Email, "Expected recording not to alter live responses")
+	require.NoError(t, recorder.Save())
+	server.Close()
+
+	cassette, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(cassette), "secret-token")
+	assert.NotContains(t, string(cassette), "jane@example.com")
+	assert.NotContains(t, string(cassette), "Jane")
+
+	replayer, err := NewRecorder(path, ModeReplay)
+	require.NoError(t, err)
+	replayClient := instruqt.NewClientWithOptions("other-token", "isovalent",
+		instruqt.WithBaseURL("http://instruqt.invalid/graphql"),
+		instruqt.WithRoundTripper(replayer),
+	)
+
+	replayedTrack, err := replayClient.GetTrackById("track-1")
+	require.NoError(t, err)
+	assert.Equal(t, track.Slug, replayedTrack.Slug)
+
+	replayedInfo, err := replayClient.GetUserInfo("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, scrubbed, replayedInfo.Email)
+	assert.Equal(t, scrubbed, replayedInfo.FirstName)
+
+	_, err = replayClient.GetTrackById("track-2")
+	assert.ErrorIs(t, err, ErrNoInteraction)
+}
+
+func TestRecorder_ReplaysInOrder(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "sandbox.json")
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	require.NoError(t, err)
+	client := server.Client(instruqt.WithRoundTripper(recorder))
+
+	_, err = client.GetSandbox("sandbox-1")
+	require.NoError(t, err)
+	require.NoError(t, client.StopSandbox("sandbox-1"))
+	_, err = client.GetSandbox("sandbox-1")
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+
+	replayer, err := NewRecorder(path, ModeReplay)
+	require.NoError(t, err)
+	replayClient := server.Client(instruqt.WithRoundTripper(replayer))
+
+	var states []string
+	for range 3 {
+		sb, err := replayClient.GetSandbox("sandbox-1")
+		require.NoError(t, err)
+		states = append(states, sb.State)
+	}
+
+	assert.Equal(t, []string{"active", "stopped", "stopped"}, states)
+	assert.Len(t, replayer.Interactions(), 3)
+}
+
+func TestNewRecorder_MissingCassette(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestScrubBody(t *testing.T) {
+	body := scrubBody([]byte(`{"data":{"user":{"id":"user-1","companyName":"Cisco","jobTitle":"SRE","token":"secret-token"}}}`), "secret-token")
+
+	assert.JSONEq(t, `{"data":{"user":{"id":"user-1","companyName":"[SCRUBBED]","jobTitle":"[SCRUBBED]","token":"[SCRUBBED]"}}}`, string(body))
+}
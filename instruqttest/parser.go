@@ -0,0 +1,366 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// operation is a parsed GraphQL operation.
+type operation struct {
+	kind       string // "query" or "mutation".
+	selections []selection
+}
+
+// selection is a field selected in a GraphQL document.
+type selection struct {
+	alias      string         // The alias of the field, or its name.
+	name       string         // The name of the field.
+	args       map[string]any // The arguments of the field, as parsed values.
+	selections []selection    // The sub-selections of the field, if any.
+}
+
+// variable references a variable in an argument value.
+type variable string
+
+// enum is an enum value in an argument value.
+type enum string
+
+// parser is a recursive-descent parser for the subset of GraphQL used by
+// the instruqt client: a single operation, with aliases, arguments and
+// nested selections.
+type parser struct {
+	src string
+	pos int
+}
+
+// parseOperation parses a GraphQL document holding a single operation.
+func parseOperation(src string) (*operation, error) {
+	p := &parser{src: src}
+	op := &operation{kind: "query"}
+
+	p.skipSpace()
+	if p.peek() != '{' {
+		op.kind = p.name()
+		if op.kind != "query" && op.kind != "mutation" {
+			return nil, p.errorf("unsupported operation %q", op.kind)
+		}
+		p.skipSpace()
+		if isNameStart(p.peek()) {
+			p.name()
+			p.skipSpace()
+		}
+		if p.peek() == '(' {
+			if err := p.skipVariableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after operation", p.src[p.pos:])
+	}
+	return op, nil
+}
+
+// selectionSet parses "{ field, field(args) { ... } }".
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+
+	var selections []selection
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '}':
+			p.pos++
+			return selections, nil
+		case 0:
+			return nil, p.errorf("unterminated selection set")
+		}
+
+		if strings.HasPrefix(p.src[p.pos:], "...") {
+			return nil, p.errorf("fragments are not supported")
+		}
+
+		sel, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+}
+
+// field parses "alias: name(args) { ... }".
+func (p *parser) field() (selection, error) {
+	name := p.name()
+	if name == "" {
+		return selection{}, p.errorf("expected field name")
+	}
+	sel := selection{alias: name, name: name}
+
+	p.skipSpace()
+	if p.peek() == ':' {
+		p.pos++
+		p.skipSpace()
+		sel.name = p.name()
+		if sel.name == "" {
+			return selection{}, p.errorf("expected field name after alias %q", name)
+		}
+		p.skipSpace()
+	}
+
+	if p.peek() == '(' {
+		args, err := p.arguments()
+		if err != nil {
+			return selection{}, err
+		}
+		sel.args = args
+		p.skipSpace()
+	}
+
+	if p.peek() == '{' {
+		selections, err := p.selectionSet()
+		if err != nil {
+			return selection{}, err
+		}
+		sel.selections = selections
+	}
+	return sel, nil
+}
+
+// arguments parses "(name: value, ...)".
+func (p *parser) arguments() (map[string]any, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	args := map[string]any{}
+	for {
+		p.skipSpace()
+		if p.peek() == ')' {
+			p.pos++
+			return args, nil
+		}
+
+		name := p.name()
+		if name == "" {
+			return nil, p.errorf("expected argument name")
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+}
+
+// value parses an argument value: a variable, an object, a list, a string,
+// a number, a boolean, null or an enum value.
+func (p *parser) value() (any, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '$':
+		p.pos++
+		return variable(p.name()), nil
+	case c == '{':
+		p.pos++
+		obj := map[string]any{}
+		for {
+			p.skipSpace()
+			if p.peek() == '}' {
+				p.pos++
+				return obj, nil
+			}
+			name := p.name()
+			if name == "" {
+				return nil, p.errorf("expected object field name")
+			}
+			if err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = v
+		}
+	case c == '[':
+		p.pos++
+		var list []any
+		for {
+			p.skipSpace()
+			if p.peek() == ']' {
+				p.pos++
+				return list, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+	case c == '"':
+		return p.string()
+	case c == '-' || unicode.IsDigit(rune(c)):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.ContainsRune("0123456789.eE+-", rune(p.src[p.pos])) {
+			p.pos++
+		}
+		return strconv.ParseFloat(p.src[start:p.pos], 64)
+	case isNameStart(c):
+		switch name := p.name(); name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return enum(name), nil
+		}
+	}
+	return nil, p.errorf("unexpected %q in value", p.peek())
+}
+
+// string parses a double-quoted string.
+func (p *parser) string() (string, error) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			return strconv.Unquote(p.src[start:p.pos])
+		default:
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// skipVariableDefinitions skips "($name: Type! ...)". Variable types are
+// not checked.
+func (p *parser) skipVariableDefinitions() error {
+	depth := 0
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				p.skipSpace()
+				return nil
+			}
+		}
+		p.pos++
+	}
+	return p.errorf("unterminated variable definitions")
+}
+
+// name parses a GraphQL name, returning an empty string if there is none.
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isNameStart(p.src[p.pos]) || unicode.IsDigit(rune(p.src[p.pos]))) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// expect consumes the given punctuation, after optional whitespace.
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// skipSpace skips whitespace and commas, which are insignificant in GraphQL.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.pos++
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the current character, or 0 at the end of the document.
+func (p *parser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// errorf returns a syntax error at the current position.
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("syntax error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// isNameStart reports whether c can start a GraphQL name.
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// resolveValue substitutes the variables referenced in an argument value.
+func resolveValue(v any, variables map[string]any) any {
+	switch v := v.(type) {
+	case variable:
+		return variables[string(v)]
+	case enum:
+		return string(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = resolveValue(value, variables)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = resolveValue(value, variables)
+		}
+		return out
+	}
+	return v
+}
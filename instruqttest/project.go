@@ -0,0 +1,105 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// project renders the fields of v selected by selections as a JSON-ready
+// value. Fields are looked up case-insensitively on structs, including
+// promoted fields, and maps with string keys, mirroring how the instruqt
+// client maps its Go types to GraphQL fields.
+func project(v any, selections []selection) (any, error) {
+	return projectValue(reflect.ValueOf(v), selections)
+}
+
+func projectValue(v reflect.Value, selections []selection) (any, error) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if len(selections) == 0 {
+		return v.Interface(), nil
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}, nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			item, err := projectValue(v.Index(i), selections)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = item
+		}
+		return out, nil
+	}
+
+	out := make(map[string]any, len(selections))
+	for _, sel := range selections {
+		if sel.name == "__typename" {
+			out[sel.alias] = v.Type().Name()
+			continue
+		}
+		field, ok := lookupField(v, sel.name)
+		if !ok {
+			return nil, fmt.Errorf("cannot query field %q on type %q", sel.name, v.Type().Name())
+		}
+		value, err := projectValue(field, sel.selections)
+		if err != nil {
+			return nil, err
+		}
+		out[sel.alias] = value
+	}
+	return out, nil
+}
+
+// lookupField returns the field or map entry of v named name, ignoring case.
+func lookupField(v reflect.Value, name string) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Struct:
+		f := v.FieldByNameFunc(func(fieldName string) bool {
+			return strings.EqualFold(fieldName, name)
+		})
+		return f, f.IsValid()
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		if e := v.MapIndex(reflect.ValueOf(name)); e.IsValid() {
+			return e, true
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if strings.EqualFold(iter.Key().String(), name) {
+				return iter.Value(), true
+			}
+		}
+		// Absent map entries are null, as for optional fields.
+		return reflect.Value{}, true
+	}
+	return reflect.Value{}, false
+}
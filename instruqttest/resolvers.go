@@ -0,0 +1,819 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/isovalent/instruqt-go/instruqt"
+)
+
+// resolver resolves a root field of a GraphQL operation from its arguments.
+// The caller must hold s.mu.
+type resolver func(s *Server, args map[string]any) (any, error)
+
+// queryResolvers are the root fields of the Query type.
+var queryResolvers = map[string]resolver{
+	"track":              (*Server).resolveTrack,
+	"tracks":             (*Server).resolveTracks,
+	"challenge":          (*Server).resolveChallenge,
+	"challenges":         (*Server).resolveChallenges,
+	"trackInvite":        (*Server).resolveTrackInvite,
+	"trackInvites":       (*Server).resolveTrackInvites,
+	"playReports":        (*Server).resolvePlayReports,
+	"playReportItem":     (*Server).resolvePlayReportItem,
+	"trackReview":        (*Server).resolveTrackReview,
+	"trackReviews":       (*Server).resolveTrackReviews,
+	"sandbox":            (*Server).resolveSandbox,
+	"sandboxes":          (*Server).resolveSandboxes,
+	"user":               (*Server).resolveUser,
+	"team":               (*Server).resolveTeam,
+	"getSandboxVariable": (*Server).resolveGetSandboxVariable,
+}
+
+// mutationResolvers are the root fields of the Mutation type.
+var mutationResolvers = map[string]resolver{
+	"stopSandbox":              (*Server).resolveStopSandbox,
+	"skipToChallenge":          (*Server).resolveSkipToChallenge,
+	"startChallenge":           (*Server).resolveStartChallenge,
+	"checkChallenge":           (*Server).resolveCheckChallenge,
+	"solveChallenge":           (*Server).resolveSolveChallenge,
+	"completeChallenge":        (*Server).resolveCompleteChallenge,
+	"setSandboxVariable":       (*Server).resolveSetSandboxVariable,
+	"generateOneTimePlayToken": (*Server).resolveGenerateOneTimePlayToken,
+	"createTrack":              (*Server).resolveCreateTrack,
+	"updateTrack":              (*Server).resolveUpdateTrack,
+	"archiveTrack":             (*Server).resolveArchiveTrack,
+	"deleteTrack":              (*Server).resolveDeleteTrack,
+	"createChallenge":          (*Server).resolveCreateChallenge,
+	"updateChallenge":          (*Server).resolveUpdateChallenge,
+	"reorderChallenges":        (*Server).resolveReorderChallenges,
+	"deleteChallenge":          (*Server).resolveDeleteChallenge,
+	"setChallengeScript":       (*Server).resolveSetChallengeScript,
+}
+
+// maintenanceTrack is a track along with its maintenance flag.
+type maintenanceTrack struct {
+	instruqt.Track
+	Maintenance bool
+}
+
+func (s *Server) resolveTrack(args map[string]any) (any, error) {
+	var track *instruqt.Track
+	if slug := stringArg(args, "trackSlug"); slug != "" {
+		track = s.findTrackBySlug(slug)
+	} else {
+		track = s.findTrack(stringArg(args, "trackID"))
+	}
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+
+	if userID := stringArg(args, "userID"); userID != "" {
+		return s.sandboxTrack(userID, *track), nil
+	}
+	return track, nil
+}
+
+func (s *Server) resolveTracks(args map[string]any) (any, error) {
+	tracks := make([]maintenanceTrack, len(s.data.Tracks))
+	for i, track := range s.data.Tracks {
+		tracks[i] = maintenanceTrack{Track: track, Maintenance: s.data.Maintenance[track.Slug]}
+	}
+	return tracks, nil
+}
+
+func (s *Server) resolveChallenge(args map[string]any) (any, error) {
+	_, ch := s.findChallenge(stringArg(args, "challengeID"))
+	if ch == nil {
+		return nil, notFoundError("challenge")
+	}
+
+	if userID := stringArg(args, "userID"); userID != "" {
+		return s.userChallenge(userID, *ch), nil
+	}
+	return *ch, nil
+}
+
+func (s *Server) resolveChallenges(args map[string]any) (any, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+	return track.Challenges, nil
+}
+
+func (s *Server) resolveTrackInvite(args map[string]any) (any, error) {
+	id := stringArg(args, "inviteID")
+	for _, invite := range s.data.Invites {
+		if invite.Id == id {
+			return invite, nil
+		}
+	}
+	return nil, notFoundError("track invite")
+}
+
+func (s *Server) resolveTrackInvites(args map[string]any) (any, error) {
+	return s.data.Invites, nil
+}
+
+func (s *Server) resolvePlayReports(args map[string]any) (any, error) {
+	input, _ := args["input"].(map[string]any)
+	dateRange, _ := input["dateRangeFilter"].(map[string]any)
+	from, err := timeArg(dateRange, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := timeArg(dateRange, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	trackIDs := stringsArg(input, "trackIds")
+	inviteIDs := stringsArg(input, "trackInviteIds")
+	userIDs := stringsArg(input, "userIds")
+	playType := stringArg(input, "playType")
+	customParameters, _ := input["customParameterFilters"].([]any)
+
+	var plays []instruqt.PlayReport
+	for _, play := range s.data.Plays {
+		switch {
+		case !from.IsZero() && play.StartedAt.Before(from),
+			!to.IsZero() && play.StartedAt.After(to),
+			len(trackIDs) > 0 && !slices.Contains(trackIDs, play.Track.Id),
+			len(inviteIDs) > 0 && !slices.Contains(inviteIDs, play.TrackInvite.Id),
+			len(userIDs) > 0 && !slices.Contains(userIDs, play.User.Id),
+			playType != "" && playType != string(instruqt.PlayTypeAll) && !strings.EqualFold(playType, play.Mode),
+			!matchesCustomParameters(play, customParameters):
+			continue
+		}
+		plays = append(plays, play)
+	}
+
+	ordering, _ := input["ordering"].(map[string]any)
+	sortPlays(plays, instruqt.OrderBy(stringArg(ordering, "orderBy")), instruqt.Direction(stringArg(ordering, "direction")))
+
+	pagination, _ := input["pagination"].(map[string]any)
+	skip := min(intArg(pagination, "skip"), len(plays))
+	end := len(plays)
+	if take := intArg(pagination, "take"); take > 0 {
+		end = min(skip+take, len(plays))
+	}
+
+	return instruqt.PlayReports{Items: plays[skip:end], TotalItems: len(plays)}, nil
+}
+
+// matchesCustomParameters reports whether play has all the custom parameters
+// in filters.
+func matchesCustomParameters(play instruqt.PlayReport, filters []any) bool {
+	for _, f := range filters {
+		filter, _ := f.(map[string]any)
+		found := false
+		for _, param := range play.CustomParameters {
+			if param.Key == stringArg(filter, "key") && param.Value == stringArg(filter, "value") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortPlays orders plays by the given field, keeping the dataset order if
+// orderBy is empty.
+func sortPlays(plays []instruqt.PlayReport, orderBy instruqt.OrderBy, direction instruqt.Direction) {
+	var key func(instruqt.PlayReport) float64
+	switch orderBy {
+	case instruqt.OrderByCompletionPercent:
+		key = func(p instruqt.PlayReport) float64 { return p.CompletionPercent }
+	case instruqt.OrderByTimeSpent:
+		key = func(p instruqt.PlayReport) float64 { return float64(p.TimeSpent) }
+	default:
+		return
+	}
+
+	slices.SortStableFunc(plays, func(a, b instruqt.PlayReport) int {
+		cmp := 0
+		switch ka, kb := key(a), key(b); {
+		case ka < kb:
+			cmp = -1
+		case ka > kb:
+			cmp = 1
+		}
+		if direction == instruqt.DirectionDesc {
+			return -cmp
+		}
+		return cmp
+	})
+}
+
+func (s *Server) resolvePlayReportItem(args map[string]any) (any, error) {
+	id := stringArg(args, "playID")
+	for _, play := range s.data.Plays {
+		if play.Id == id {
+			return play, nil
+		}
+	}
+	return nil, notFoundError("play")
+}
+
+func (s *Server) resolveTrackReview(args map[string]any) (any, error) {
+	id := stringArg(args, "reviewID")
+	for _, reviews := range s.data.Reviews {
+		for _, review := range reviews {
+			if review.Id == id {
+				return review, nil
+			}
+		}
+	}
+	return nil, notFoundError("review")
+}
+
+func (s *Server) resolveTrackReviews(args map[string]any) (any, error) {
+	reviews := s.data.Reviews[stringArg(args, "trackID")]
+	return struct {
+		TotalCount int
+		Nodes      []instruqt.Review
+	}{len(reviews), reviews}, nil
+}
+
+func (s *Server) resolveSandbox(args map[string]any) (any, error) {
+	sb := s.findSandbox(stringArg(args, "ID"))
+	if sb == nil {
+		return nil, notFoundError("sandbox")
+	}
+	return sb, nil
+}
+
+func (s *Server) resolveSandboxes(args map[string]any) (any, error) {
+	filter, _ := args["filter"].(map[string]any)
+	trackIDs := stringsArg(filter, "track_ids")
+	inviteIDs := stringsArg(filter, "invite_ids")
+	poolIDs := stringsArg(filter, "pool_ids")
+	userID := stringArg(filter, "user_name_or_id")
+	states := stringsArg(filter, "state")
+
+	var sandboxes []instruqt.Sandbox
+	for _, sb := range s.data.Sandboxes {
+		var poolID string
+		if sb.Hot_Start_Pool != nil {
+			poolID = sb.Hot_Start_Pool.Id
+		}
+		switch {
+		case len(trackIDs) > 0 && !slices.Contains(trackIDs, sb.Track.Id),
+			len(inviteIDs) > 0 && !slices.Contains(inviteIDs, sb.Invite.Id),
+			len(poolIDs) > 0 && !slices.Contains(poolIDs, poolID),
+			userID != "" && userID != sb.User.Id,
+			len(states) > 0 && !slices.Contains(states, sb.State):
+			continue
+		}
+		sandboxes = append(sandboxes, sb)
+	}
+
+	return struct{ Nodes []instruqt.Sandbox }{sandboxes}, nil
+}
+
+func (s *Server) resolveUser(args map[string]any) (any, error) {
+	id := stringArg(args, "userID")
+	for _, user := range s.data.Users {
+		if user.Id == id {
+			return user, nil
+		}
+	}
+	return nil, notFoundError("user")
+}
+
+func (s *Server) resolveTeam(args map[string]any) (any, error) {
+	if slug := stringArg(args, "teamSlug"); slug != s.data.TeamSlug {
+		return nil, notFoundError("team")
+	}
+	return map[string]any{"tpgPublicKey": s.data.TPGPublicKey}, nil
+}
+
+func (s *Server) resolveGetSandboxVariable(args map[string]any) (any, error) {
+	key := stringArg(args, "key")
+	value := s.data.SandboxVariables[stringArg(args, "sandboxID")][stringArg(args, "hostname")][key]
+	return instruqt.SandboxVar{Key: key, Value: value}, nil
+}
+
+func (s *Server) resolveStopSandbox(args map[string]any) (any, error) {
+	sb := s.findSandbox(stringArg(args, "sandboxID"))
+	if sb == nil {
+		return nil, notFoundError("sandbox")
+	}
+	sb.State = string(instruqt.SandboxStateStopped)
+	return struct{ Id string }{sb.Id}, nil
+}
+
+// resolveSkipToChallenge completes the challenges of the track preceding the
+// given challenge for the user, and unlocks the challenge.
+func (s *Server) resolveSkipToChallenge(args map[string]any) (any, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+
+	challengeID := stringArg(args, "challengeID")
+	target := slices.IndexFunc(track.Challenges, func(ch instruqt.Challenge) bool { return ch.Id == challengeID })
+	if target < 0 {
+		return nil, notFoundError("challenge")
+	}
+
+	progress := s.userProgress(stringArg(args, "userID"))
+	for _, ch := range track.Challenges[:target] {
+		progress[ch.Id] = "completed"
+	}
+	progress[challengeID] = "unlocked"
+
+	return struct{ Id, Status string }{challengeID, "unlocked"}, nil
+}
+
+func (s *Server) resolveStartChallenge(args map[string]any) (any, error) {
+	userID, track, i, err := s.challengeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := track.Challenges[i]
+	switch s.challengeStatus(userID, ch) {
+	case "completed", "started":
+	case "locked":
+		// The first challenge of a track can always be started.
+		if i > 0 {
+			return nil, fmt.Errorf("challenge %s is locked", ch.Id)
+		}
+		fallthrough
+	default:
+		s.userProgress(userID)[ch.Id] = "started"
+	}
+	return s.userChallenge(userID, ch), nil
+}
+
+func (s *Server) resolveCheckChallenge(args map[string]any) (any, error) {
+	userID, track, i, err := s.startedChallengeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := track.Challenges[i]
+	message := s.data.CheckFailures[ch.Id]
+	if s.solved[[2]string{userID, ch.Id}] {
+		message = ""
+	}
+	if s.data.Attempts[userID] == nil {
+		s.data.Attempts[userID] = map[string][]instruqt.ChallengeAttempt{}
+	}
+	s.data.Attempts[userID][ch.Id] = append(s.data.Attempts[userID][ch.Id], instruqt.ChallengeAttempt{
+		Message:   message,
+		Timestamp: time.Now().UTC(),
+	})
+	if message == "" {
+		s.completeChallenge(userID, track, i)
+	}
+	return s.userChallenge(userID, ch), nil
+}
+
+func (s *Server) resolveSolveChallenge(args map[string]any) (any, error) {
+	userID, track, i, err := s.startedChallengeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := track.Challenges[i]
+	s.solved[[2]string{userID, ch.Id}] = true
+	return s.userChallenge(userID, ch), nil
+}
+
+func (s *Server) resolveCompleteChallenge(args map[string]any) (any, error) {
+	userID, track, i, err := s.startedChallengeArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	s.completeChallenge(userID, track, i)
+	return s.userChallenge(userID, track.Challenges[i]), nil
+}
+
+func (s *Server) resolveSetSandboxVariable(args map[string]any) (any, error) {
+	sandboxID := stringArg(args, "sandboxID")
+	if s.findSandbox(sandboxID) == nil {
+		return nil, notFoundError("sandbox")
+	}
+
+	hostname, key, value := stringArg(args, "hostname"), stringArg(args, "key"), stringArg(args, "value")
+	if s.data.SandboxVariables[sandboxID] == nil {
+		s.data.SandboxVariables[sandboxID] = map[string]map[string]string{}
+	}
+	if s.data.SandboxVariables[sandboxID][hostname] == nil {
+		s.data.SandboxVariables[sandboxID][hostname] = map[string]string{}
+	}
+	s.data.SandboxVariables[sandboxID][hostname][key] = value
+
+	return instruqt.SandboxVar{Key: key, Value: value}, nil
+}
+
+func (s *Server) resolveGenerateOneTimePlayToken(args map[string]any) (any, error) {
+	trackID := stringArg(args, "trackID")
+	if s.findTrack(trackID) == nil {
+		return nil, notFoundError("track")
+	}
+	return s.nextID(trackID + "-token"), nil
+}
+
+func (s *Server) resolveCreateTrack(args map[string]any) (any, error) {
+	input, _ := args["track"].(map[string]any)
+	slug := stringArg(input, "slug")
+	if s.findTrackBySlug(slug) != nil {
+		return nil, fmt.Errorf("track with slug %q already exists", slug)
+	}
+
+	now := s.now()
+	s.data.Tracks = append(s.data.Tracks, instruqt.Track{
+		Id:          s.nextID("track"),
+		CreatedAt:   now,
+		Last_Update: now,
+	})
+	track := &s.data.Tracks[len(s.data.Tracks)-1]
+	s.applyTrackInput(track, input)
+	return track, nil
+}
+
+func (s *Server) resolveUpdateTrack(args map[string]any) (any, error) {
+	input, _ := args["track"].(map[string]any)
+	track := s.findTrack(stringArg(input, "id"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+	s.applyTrackInput(track, input)
+	track.Last_Update = s.now()
+	return track, nil
+}
+
+// applyTrackInput sets the fields present in a TrackInput on track.
+func (s *Server) applyTrackInput(track *instruqt.Track, input map[string]any) {
+	for key, field := range map[string]*string{
+		"slug":        &track.Slug,
+		"title":       &track.Title,
+		"teaser":      &track.Teaser,
+		"description": &track.Description,
+		"level":       &track.Level,
+		"icon":        &track.Icon,
+	} {
+		if v, ok := input[key].(string); ok {
+			*field = v
+		}
+	}
+	if _, ok := input["tags"]; ok {
+		track.TrackTags = nil
+		for _, tag := range stringsArg(input, "tags") {
+			track.TrackTags = append(track.TrackTags, instruqt.TrackTag{Value: tag})
+		}
+	}
+	if maintenance, ok := input["maintenance"].(bool); ok {
+		if s.data.Maintenance == nil {
+			s.data.Maintenance = map[string]bool{}
+		}
+		s.data.Maintenance[track.Slug] = maintenance
+	}
+}
+
+func (s *Server) resolveArchiveTrack(args map[string]any) (any, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+	track.DeletedAt = s.now()
+	return track, nil
+}
+
+func (s *Server) resolveDeleteTrack(args map[string]any) (any, error) {
+	id := stringArg(args, "trackID")
+	i := slices.IndexFunc(s.data.Tracks, func(t instruqt.Track) bool { return t.Id == id })
+	if i < 0 {
+		return nil, notFoundError("track")
+	}
+	s.data.Tracks = slices.Delete(s.data.Tracks, i, i+1)
+	return true, nil
+}
+
+func (s *Server) resolveCreateChallenge(args map[string]any) (any, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+
+	input, _ := args["challenge"].(map[string]any)
+	ch := instruqt.Challenge{
+		Id:     s.nextID("challenge"),
+		Index:  len(track.Challenges),
+		Status: "locked",
+	}
+	ch.Track.Id = track.Id
+	applyChallengeInput(&ch, input)
+	track.Challenges = append(track.Challenges, ch)
+	return ch, nil
+}
+
+func (s *Server) resolveUpdateChallenge(args map[string]any) (any, error) {
+	input, _ := args["challenge"].(map[string]any)
+	_, ch := s.findChallenge(stringArg(input, "id"))
+	if ch == nil {
+		return nil, notFoundError("challenge")
+	}
+	applyChallengeInput(ch, input)
+	return ch, nil
+}
+
+// applyChallengeInput sets the fields present in a ChallengeInput on ch.
+// Fields that Challenge does not expose, such as notes, are ignored.
+func applyChallengeInput(ch *instruqt.Challenge, input map[string]any) {
+	for key, field := range map[string]*string{
+		"slug":       &ch.Slug,
+		"title":      &ch.Title,
+		"teaser":     &ch.Teaser,
+		"type":       &ch.Type,
+		"assignment": &ch.Assignment,
+	} {
+		if v, ok := input[key].(string); ok {
+			*field = v
+		}
+	}
+}
+
+func (s *Server) resolveReorderChallenges(args map[string]any) (any, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return nil, notFoundError("track")
+	}
+
+	ids := stringsArg(args, "challengeIDs")
+	if len(ids) != len(track.Challenges) {
+		return nil, fmt.Errorf("expected %d challenge IDs, got %d", len(track.Challenges), len(ids))
+	}
+	reordered := make([]instruqt.Challenge, len(ids))
+	for i, id := range ids {
+		j := slices.IndexFunc(track.Challenges, func(ch instruqt.Challenge) bool { return ch.Id == id })
+		if j < 0 {
+			return nil, notFoundError("challenge")
+		}
+		reordered[i] = track.Challenges[j]
+		reordered[i].Index = i
+	}
+	track.Challenges = reordered
+	return track.Challenges, nil
+}
+
+func (s *Server) resolveDeleteChallenge(args map[string]any) (any, error) {
+	track, ch := s.findChallenge(stringArg(args, "challengeID"))
+	if ch == nil {
+		return nil, notFoundError("challenge")
+	}
+
+	id := ch.Id
+	track.Challenges = slices.DeleteFunc(track.Challenges, func(ch instruqt.Challenge) bool { return ch.Id == id })
+	for i := range track.Challenges {
+		track.Challenges[i].Index = i
+	}
+	delete(s.data.Scripts, id)
+	return true, nil
+}
+
+func (s *Server) resolveSetChallengeScript(args map[string]any) (any, error) {
+	_, ch := s.findChallenge(stringArg(args, "challengeID"))
+	if ch == nil {
+		return nil, notFoundError("challenge")
+	}
+
+	script := instruqt.ChallengeScript{
+		Host:     stringArg(args, "host"),
+		Action:   instruqt.ChallengeScriptAction(stringArg(args, "action")),
+		Contents: stringArg(args, "contents"),
+	}
+	scripts := slices.DeleteFunc(s.data.Scripts[ch.Id], func(sc instruqt.ChallengeScript) bool {
+		return sc.Host == script.Host && sc.Action == script.Action
+	})
+	if script.Contents != "" {
+		scripts = append(scripts, script)
+	}
+	if s.data.Scripts == nil {
+		s.data.Scripts = map[string][]instruqt.ChallengeScript{}
+	}
+	s.data.Scripts[ch.Id] = scripts
+	return script, nil
+}
+
+// nextID returns a new unique identifier with the given prefix.
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-generated-%d", prefix, s.seq)
+}
+
+// now returns the current time, truncated to seconds as returned by the API.
+func (s *Server) now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// findTrack returns the track with the given ID, or nil.
+func (s *Server) findTrack(id string) *instruqt.Track {
+	for i := range s.data.Tracks {
+		if s.data.Tracks[i].Id == id {
+			return &s.data.Tracks[i]
+		}
+	}
+	return nil
+}
+
+// findTrackBySlug returns the track with the given slug, or nil.
+func (s *Server) findTrackBySlug(slug string) *instruqt.Track {
+	for i := range s.data.Tracks {
+		if s.data.Tracks[i].Slug == slug {
+			return &s.data.Tracks[i]
+		}
+	}
+	return nil
+}
+
+// findChallenge returns the challenge with the given ID along with its
+// track, or nils. The challenge's Track.Id is set to the ID of its track.
+func (s *Server) findChallenge(id string) (*instruqt.Track, *instruqt.Challenge) {
+	for i := range s.data.Tracks {
+		track := &s.data.Tracks[i]
+		for j := range track.Challenges {
+			if ch := &track.Challenges[j]; ch.Id == id {
+				ch.Track.Id = track.Id
+				return track, ch
+			}
+		}
+	}
+	return nil, nil
+}
+
+// challengeArgs returns the user, the track and the index of the challenge
+// of the arguments of a challenge mutation.
+func (s *Server) challengeArgs(args map[string]any) (string, *instruqt.Track, int, error) {
+	track := s.findTrack(stringArg(args, "trackID"))
+	if track == nil {
+		return "", nil, 0, notFoundError("track")
+	}
+
+	challengeID := stringArg(args, "challengeID")
+	i := slices.IndexFunc(track.Challenges, func(ch instruqt.Challenge) bool { return ch.Id == challengeID })
+	if i < 0 {
+		return "", nil, 0, notFoundError("challenge")
+	}
+	track.Challenges[i].Track.Id = track.Id
+	return stringArg(args, "userID"), track, i, nil
+}
+
+// startedChallengeArgs is like challengeArgs, but fails unless the user
+// started the challenge.
+func (s *Server) startedChallengeArgs(args map[string]any) (string, *instruqt.Track, int, error) {
+	userID, track, i, err := s.challengeArgs(args)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	if ch := track.Challenges[i]; s.challengeStatus(userID, ch) != "started" {
+		return "", nil, 0, fmt.Errorf("challenge %s is not started", ch.Id)
+	}
+	return userID, track, i, nil
+}
+
+// completeChallenge completes the challenge at index i of track for the
+// user, and unlocks the next one.
+func (s *Server) completeChallenge(userID string, track *instruqt.Track, i int) {
+	progress := s.userProgress(userID)
+	progress[track.Challenges[i].Id] = "completed"
+	if i+1 < len(track.Challenges) {
+		if next := track.Challenges[i+1]; s.challengeStatus(userID, next) == "locked" {
+			progress[next.Id] = "unlocked"
+		}
+	}
+}
+
+// userProgress returns the progress of the user, creating it if needed.
+func (s *Server) userProgress(userID string) map[string]string {
+	progress := s.data.Progress[userID]
+	if progress == nil {
+		progress = map[string]string{}
+		s.data.Progress[userID] = progress
+	}
+	return progress
+}
+
+// userChallenge returns the view of ch for the user.
+func (s *Server) userChallenge(userID string, ch instruqt.Challenge) instruqt.Challenge {
+	ch.Status = s.challengeStatus(userID, ch)
+	ch.Attempts = slices.Clone(s.data.Attempts[userID][ch.Id])
+	return ch
+}
+
+// findSandbox returns the sandbox with the given ID, or nil.
+func (s *Server) findSandbox(id string) *instruqt.Sandbox {
+	for i := range s.data.Sandboxes {
+		if s.data.Sandboxes[i].Id == id {
+			return &s.data.Sandboxes[i]
+		}
+	}
+	return nil
+}
+
+// challengeStatus returns the status of ch for the user, falling back to
+// the status set in the dataset.
+func (s *Server) challengeStatus(userID string, ch instruqt.Challenge) string {
+	if status, ok := s.data.Progress[userID][ch.Id]; ok {
+		return status
+	}
+	return ch.Status
+}
+
+// sandboxTrack returns the view of track for the user.
+func (s *Server) sandboxTrack(userID string, track instruqt.Track) instruqt.SandboxTrack {
+	st := instruqt.SandboxTrack{
+		Id:          track.Id,
+		Slug:        track.Slug,
+		Icon:        track.Icon,
+		Title:       track.Title,
+		Description: track.Description,
+		Teaser:      track.Teaser,
+		Level:       track.Level,
+		Embed_Token: track.Embed_Token,
+		CreatedAt:   track.CreatedAt,
+		DeletedAt:   track.DeletedAt,
+		Last_Update: track.Last_Update,
+		Statistics:  track.Statistics,
+		TrackTags:   track.TrackTags,
+	}
+	st.Participant.Id = userID
+
+	completed := 0
+	for _, ch := range track.Challenges {
+		ch.Track.Id = track.Id
+		ch = s.userChallenge(userID, ch)
+		if ch.Status == "completed" {
+			completed++
+		}
+		st.Challenges = append(st.Challenges, ch)
+	}
+	switch {
+	case len(track.Challenges) > 0 && completed == len(track.Challenges):
+		st.Status = "completed"
+	case len(s.data.Progress[userID]) > 0:
+		st.Status = "started"
+	}
+	return st
+}
+
+// stringArg returns the string argument name, or an empty string.
+func stringArg(args map[string]any, name string) string {
+	v, _ := args[name].(string)
+	return v
+}
+
+// stringsArg returns the list of strings argument name.
+func stringsArg(args map[string]any, name string) []string {
+	list, _ := args[name].([]any)
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// intArg returns the integer argument name, or 0.
+func intArg(args map[string]any, name string) int {
+	v, _ := args[name].(float64)
+	return int(v)
+}
+
+// timeArg returns the RFC 3339 time argument name, or the zero time.
+func timeArg(args map[string]any, name string) (time.Time, error) {
+	v := stringArg(args, name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
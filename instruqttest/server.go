@@ -0,0 +1,316 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package instruqttest provides an in-process fake of the Instruqt GraphQL
+// API for tests. The server speaks enough of the Instruqt schema to serve
+// the queries and mutations made by the instruqt client from a seeded
+// in-memory dataset, so tests exercise the real GraphQL documents instead
+// of hand-populated query structs.
+//
+// Usage:
+//
+//	server := instruqttest.NewServer(instruqttest.Dataset{
+//		TeamSlug: "isovalent",
+//		Tracks:   []instruqt.Track{{Id: "track-1", Slug: "getting-started"}},
+//	})
+//	defer server.Close()
+//
+//	client := server.Client()
+//	track, err := client.GetTrackBySlug("getting-started")
+package instruqttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/isovalent/instruqt-go/instruqt"
+)
+
+// Dataset is the in-memory data served by a Server.
+type Dataset struct {
+	TeamSlug     string // The slug of the team owning the data.
+	TPGPublicKey string // The public key returned for the team.
+	Token        string // If set, the bearer token requests must carry.
+
+	Tracks    []instruqt.Track       // The tracks of the team, with their Challenges.
+	Invites   []instruqt.TrackInvite // The track invites of the team, with their Tracks.
+	Plays     []instruqt.PlayReport  // The play reports of the team.
+	Sandboxes []instruqt.Sandbox     // The sandboxes of the team.
+	Users     []instruqt.User        // The users known to the team.
+
+	// Reviews holds the reviews of each track, by track ID.
+	Reviews map[string][]instruqt.Review
+	// Progress holds the status of challenges for each user, by user ID and
+	// challenge ID. Challenges without progress have the status set in
+	// Tracks.
+	Progress map[string]map[string]string
+	// Attempts holds the checks of challenges for each user, by user ID and
+	// challenge ID. Passed checks have an empty message.
+	Attempts map[string]map[string][]instruqt.ChallengeAttempt
+	// CheckFailures holds the message of the check of challenges, by challenge
+	// ID. Checks of these challenges fail until they are solved, while checks
+	// of other challenges pass.
+	CheckFailures map[string]string
+	// SandboxVariables holds sandbox variables, by sandbox ID, hostname and key.
+	SandboxVariables map[string]map[string]map[string]string
+	// Maintenance holds whether tracks are in maintenance, by track slug.
+	Maintenance map[string]bool
+	// Scripts holds the lifecycle scripts of challenges, by challenge ID.
+	Scripts map[string][]instruqt.ChallengeScript
+}
+
+// Request is a GraphQL request received by a Server.
+type Request struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// Server is a fake Instruqt GraphQL API served over HTTP.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     Dataset
+	requests []Request
+	seq      int                // The last sequence number used for generated IDs and tokens.
+	solved   map[[2]string]bool // The challenges solved by users, by user ID and challenge ID.
+}
+
+// NewServer starts a Server serving a copy of data. The caller should call
+// Close when finished, to shut it down.
+func NewServer(data Dataset) *Server {
+	s := &Server{data: cloneDataset(data), solved: map[[2]string]bool{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an instruqt client talking to the server, configured with
+// the given options.
+func (s *Server) Client(opts ...instruqt.ClientOption) *instruqt.Client {
+	token := s.data.Token
+	if token == "" {
+		token = "test-token"
+	}
+	opts = append([]instruqt.ClientOption{instruqt.WithBaseURL(s.URL)}, opts...)
+	return instruqt.NewClientWithOptions(token, s.data.TeamSlug, opts...)
+}
+
+// Requests returns the GraphQL requests received by the server so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Sandbox returns the current state of a sandbox.
+func (s *Server) Sandbox(id string) (instruqt.Sandbox, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sb := s.findSandbox(id); sb != nil {
+		return *sb, true
+	}
+	return instruqt.Sandbox{}, false
+}
+
+// ChallengeStatus returns the current status of a challenge for a user.
+func (s *Server) ChallengeStatus(userID string, challengeID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ch := s.findChallenge(challengeID)
+	if ch == nil {
+		return ""
+	}
+	return s.challengeStatus(userID, *ch)
+}
+
+// ChallengeScripts returns the current lifecycle scripts of a challenge.
+func (s *Server) ChallengeScripts(challengeID string) []instruqt.ChallengeScript {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Scripts[challengeID])
+}
+
+// SandboxVariable returns the current value of a sandbox variable.
+func (s *Server) SandboxVariable(sandboxID string, hostname string, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.data.SandboxVariables[sandboxID][hostname][key]
+	return value, ok
+}
+
+// graphQLError is an error in a GraphQL response.
+type graphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// serveHTTP handles a GraphQL request.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.data.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.data.Token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	data, errs := s.execute(req)
+	s.mu.Unlock()
+
+	resp := map[string]any{"data": data}
+	if len(errs) > 0 {
+		resp["errors"] = errs
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// execute runs a GraphQL request against the dataset. The caller must hold s.mu.
+func (s *Server) execute(req Request) (map[string]any, []graphQLError) {
+	op, err := parseOperation(req.Query)
+	if err != nil {
+		return nil, []graphQLError{{
+			Message:    err.Error(),
+			Extensions: map[string]any{"code": "GRAPHQL_PARSE_FAILED"},
+		}}
+	}
+
+	resolvers := queryResolvers
+	if op.kind == "mutation" {
+		resolvers = mutationResolvers
+	}
+
+	data := map[string]any{}
+	var errs []graphQLError
+	for _, sel := range op.selections {
+		resolve, ok := resolvers[sel.name]
+		if !ok {
+			return nil, []graphQLError{{
+				Message:    "Cannot query field \"" + sel.name + "\" on type \"" + strings.ToUpper(op.kind[:1]) + op.kind[1:] + "\".",
+				Extensions: map[string]any{"code": "GRAPHQL_VALIDATION_FAILED"},
+			}}
+		}
+
+		args := make(map[string]any, len(sel.args))
+		for name, value := range sel.args {
+			args[name] = resolveValue(value, req.Variables)
+		}
+
+		result, err := resolve(s, args)
+		if err == nil {
+			data[sel.alias], err = project(result, sel.selections)
+		}
+		if err != nil {
+			data[sel.alias] = nil
+			errs = append(errs, toGraphQLError(err, sel.alias))
+		}
+	}
+	return data, errs
+}
+
+// notFoundError is returned by resolvers when an entity does not exist.
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) + " not found" }
+
+// toGraphQLError converts a resolver error into a GraphQL error.
+func toGraphQLError(err error, alias string) graphQLError {
+	gqlErr := graphQLError{Message: err.Error(), Path: []any{alias}}
+	if _, ok := err.(notFoundError); ok {
+		gqlErr.Extensions = map[string]any{"code": "NOT_FOUND"}
+	}
+	return gqlErr
+}
+
+// cloneDataset copies the slices and maps of data, so that mutations do not
+// affect the caller's dataset.
+func cloneDataset(data Dataset) Dataset {
+	data.Tracks = append([]instruqt.Track(nil), data.Tracks...)
+	for i := range data.Tracks {
+		data.Tracks[i].Challenges = append([]instruqt.Challenge(nil), data.Tracks[i].Challenges...)
+	}
+	data.Invites = append([]instruqt.TrackInvite(nil), data.Invites...)
+	data.Plays = append([]instruqt.PlayReport(nil), data.Plays...)
+	data.Sandboxes = append([]instruqt.Sandbox(nil), data.Sandboxes...)
+	data.Users = append([]instruqt.User(nil), data.Users...)
+
+	progress := make(map[string]map[string]string, len(data.Progress))
+	for user, statuses := range data.Progress {
+		progress[user] = make(map[string]string, len(statuses))
+		for id, status := range statuses {
+			progress[user][id] = status
+		}
+	}
+	data.Progress = progress
+
+	attempts := make(map[string]map[string][]instruqt.ChallengeAttempt, len(data.Attempts))
+	for user, challenges := range data.Attempts {
+		attempts[user] = make(map[string][]instruqt.ChallengeAttempt, len(challenges))
+		for id, a := range challenges {
+			attempts[user][id] = slices.Clone(a)
+		}
+	}
+	data.Attempts = attempts
+
+	failures := make(map[string]string, len(data.CheckFailures))
+	for id, message := range data.CheckFailures {
+		failures[id] = message
+	}
+	data.CheckFailures = failures
+
+	variables := make(map[string]map[string]map[string]string, len(data.SandboxVariables))
+	for sandbox, hosts := range data.SandboxVariables {
+		variables[sandbox] = make(map[string]map[string]string, len(hosts))
+		for host, vars := range hosts {
+			variables[sandbox][host] = make(map[string]string, len(vars))
+			for key, value := range vars {
+				variables[sandbox][host][key] = value
+			}
+		}
+	}
+	data.SandboxVariables = variables
+
+	scripts := make(map[string][]instruqt.ChallengeScript, len(data.Scripts))
+	for id, s := range data.Scripts {
+		scripts[id] = slices.Clone(s)
+	}
+	data.Scripts = scripts
+
+	reviews := make(map[string][]instruqt.Review, len(data.Reviews))
+	for id, r := range data.Reviews {
+		reviews[id] = slices.Clone(r)
+	}
+	data.Reviews = reviews
+
+	maintenance := make(map[string]bool, len(data.Maintenance))
+	for slug, m := range data.Maintenance {
+		maintenance[slug] = m
+	}
+	data.Maintenance = maintenance
+	return data
+}
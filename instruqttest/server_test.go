@@ -0,0 +1,593 @@
+// Copyright 2024 Cisco Systems, Inc. and its affiliates
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instruqttest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isovalent/instruqt-go/instruqt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDataset returns a small dataset with one track of three challenges.
+func testDataset() Dataset {
+	started := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	var plays []instruqt.PlayReport
+	for i, userID := range []string{"user-1", "user-2", "user-3", "user-1", "user-2"} {
+		play := instruqt.PlayReport{
+			Id:                "play-" + string(rune('a'+i)),
+			CompletionPercent: float64(i * 20),
+			Mode:              "NORMAL",
+			StartedAt:         started.Add(time.Duration(i) * time.Hour),
+		}
+		play.Track.Id = "track-1"
+		play.User.Id = userID
+		plays = append(plays, play)
+	}
+
+	sandbox := instruqt.Sandbox{Id: "sandbox-1", State: "active"}
+	sandbox.Track.Id = "track-1"
+	sandbox.User.Id = "user-1"
+
+	return Dataset{
+		TeamSlug:     "isovalent",
+		TPGPublicKey: "public-key",
+		Tracks: []instruqt.Track{{
+			Id:        "track-1",
+			Slug:      "getting-started",
+			Title:     "Getting Started",
+			TrackTags: []instruqt.TrackTag{{Value: "cilium"}},
+			Challenges: []instruqt.Challenge{
+				{Id: "ch-1", Slug: "intro", Title: "Introduction", Index: 0, Status: "locked"},
+				{Id: "ch-2", Slug: "install", Title: "Install", Index: 1, Status: "locked"},
+				{Id: "ch-3", Slug: "observe", Title: "Observe", Index: 2, Status: "locked"},
+			},
+		}},
+		Invites: []instruqt.TrackInvite{{
+			Id:     "invite-1",
+			Title:  "Workshop",
+			Tracks: []instruqt.Track{{Id: "track-1", Slug: "getting-started"}},
+		}},
+		Plays:     plays,
+		Sandboxes: []instruqt.Sandbox{sandbox},
+		Users: []instruqt.User{{
+			Id:      "user-1",
+			Details: &instruqt.UserDetails{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com"},
+		}},
+		Progress: map[string]map[string]string{
+			"user-1": {"ch-1": "completed", "ch-2": "unlocked"},
+		},
+	}
+}
+
+func TestServer_GetTrack(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	track, err := client.GetTrackById("track-1")
+	require.NoError(t, err)
+	assert.Equal(t, "getting-started", track.Slug)
+	assert.Equal(t, []instruqt.TrackTag{{Value: "cilium"}}, track.TrackTags)
+
+	track, err = client.GetTrackBySlug("getting-started", instruqt.WithChallenges())
+	require.NoError(t, err)
+	assert.Equal(t, "track-1", track.Id)
+	assert.Len(t, track.Challenges, 3)
+}
+
+func TestServer_GetUserTrack(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+
+	for name, opts := range map[string][]instruqt.Option{
+		"per challenge": {instruqt.WithChallenges()},
+		"batched":       {instruqt.WithChallenges(), instruqt.WithBatchSize(2)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			track, err := server.Client().GetUserTrackById("user-1", "track-1", opts...)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", track.Participant.Id)
+			require.Len(t, track.Challenges, 3)
+			assert.Equal(t, "completed", track.Challenges[0].Status)
+			assert.Equal(t, "unlocked", track.Challenges[1].Status)
+			assert.Equal(t, "locked", track.Challenges[2].Status)
+		})
+	}
+}
+
+func TestServer_GetInvite(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+
+	invite, err := server.Client().GetInvite("invite-1", instruqt.WithTracks())
+	require.NoError(t, err)
+	assert.Equal(t, "Workshop", invite.Title)
+	require.Len(t, invite.Tracks, 1)
+	assert.Equal(t, "getting-started", invite.Tracks[0].Slug)
+}
+
+func TestServer_AllPlays(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	var ids []string
+	for play, err := range server.Client().AllPlays(from, to, instruqt.WithPageSize(2), instruqt.WithUserIDs("user-1", "user-2")) {
+		require.NoError(t, err)
+		ids = append(ids, play.Id)
+	}
+
+	// Plays are ordered by completion percentage, descending, by default.
+	assert.Equal(t, []string{"play-e", "play-d", "play-b", "play-a"}, ids)
+	assert.Len(t, server.Requests(), 2)
+}
+
+func TestServer_GetUserInfo(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+
+	info, err := server.Client().GetUserInfo("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", info.Email)
+}
+
+func TestServer_StopSandbox(t *testing.T) {
+	data := testDataset()
+	server := NewServer(data)
+	defer server.Close()
+	client := server.Client()
+
+	require.NoError(t, client.StopSandbox("sandbox-1"))
+
+	sb, ok := server.Sandbox("sandbox-1")
+	require.True(t, ok)
+	assert.Equal(t, "stopped", sb.State)
+	assert.Equal(t, "active", data.Sandboxes[0].State, "Expected the seeded dataset not to be modified")
+
+	sandboxes, err := client.GetSandboxes(instruqt.WithStates(instruqt.SandboxStateActive))
+	require.NoError(t, err)
+	assert.Empty(t, sandboxes)
+}
+
+func TestServer_SkipToChallenge(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	require.NoError(t, client.SkipToChallenge("user-2", "track-1", "ch-3"))
+
+	assert.Equal(t, "completed", server.ChallengeStatus("user-2", "ch-1"))
+	assert.Equal(t, "completed", server.ChallengeStatus("user-2", "ch-2"))
+	assert.Equal(t, "unlocked", server.ChallengeStatus("user-2", "ch-3"))
+
+	ch, err := client.GetUserChallenge("user-2", "ch-3")
+	require.NoError(t, err)
+	assert.Equal(t, "unlocked", ch.Status)
+}
+
+func TestServer_PlayTrack(t *testing.T) {
+	data := testDataset()
+	data.CheckFailures = map[string]string{"ch-2": "cilium is not installed"}
+	server := NewServer(data)
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.StartChallenge("user-2", "track-1", "ch-2")
+	assert.ErrorContains(t, err, "challenge ch-2 is locked")
+
+	_, err = client.CheckChallenge("user-2", "track-1", "ch-1")
+	assert.ErrorContains(t, err, "challenge ch-1 is not started")
+
+	ch, err := client.StartChallenge("user-2", "track-1", "ch-1")
+	require.NoError(t, err)
+	assert.Equal(t, "started", ch.Status)
+	ch, err = client.CompleteChallenge("user-2", "track-1", "ch-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", ch.Status)
+	assert.Equal(t, "unlocked", server.ChallengeStatus("user-2", "ch-2"))
+
+	_, err = client.StartChallenge("user-2", "track-1", "ch-2")
+	require.NoError(t, err)
+	check, err := client.CheckChallenge("user-2", "track-1", "ch-2")
+	require.NoError(t, err)
+	assert.False(t, check.Passed)
+	assert.Equal(t, "cilium is not installed", check.Message)
+	require.Len(t, check.Challenge.Attempts, 1)
+	assert.False(t, check.Challenge.Attempts[0].Timestamp.IsZero())
+
+	_, err = client.SolveChallenge("user-2", "track-1", "ch-2")
+	require.NoError(t, err)
+	check, err = client.CheckChallenge("user-2", "track-1", "ch-2")
+	require.NoError(t, err)
+	assert.True(t, check.Passed)
+	assert.Empty(t, check.Message)
+	assert.Equal(t, "unlocked", server.ChallengeStatus("user-2", "ch-3"))
+
+	ch, err = client.GetUserChallenge("user-2", "ch-2")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", ch.Status)
+	require.Len(t, ch.Attempts, 2)
+	assert.Equal(t, "cilium is not installed", ch.Attempts[0].Message)
+	assert.Empty(t, ch.Attempts[1].Message)
+}
+
+func TestServer_TrackTester(t *testing.T) {
+	data := testDataset()
+	data.CheckFailures = map[string]string{"ch-2": "cilium is not installed"}
+	server := NewServer(data)
+	defer server.Close()
+
+	launch := func(ctx context.Context, track instruqt.Track) (instruqt.TrackSession, error) {
+		if track.Id != "track-1" {
+			return instruqt.TrackSession{}, fmt.Errorf("no sandbox for track %s", track.Id)
+		}
+		return instruqt.TrackSession{UserId: "user-2", SandboxId: "sandbox-1"}, nil
+	}
+	tester := instruqt.NewTrackTester(server.Client(), launch, instruqt.WithTrackConcurrency(2))
+	report, err := tester.Run("track-1", "missing")
+	require.NoError(t, err)
+
+	require.Len(t, report.Tracks, 2)
+	assert.True(t, report.Tracks[0].Passed())
+	require.Len(t, report.Tracks[0].Challenges, 3)
+	for _, ch := range report.Tracks[0].Challenges {
+		assert.Equal(t, instruqt.ChallengeTestPassed, ch.Status, ch.ChallengeSlug)
+	}
+	assert.Contains(t, report.Tracks[1].Error, "failed to get track")
+	assert.False(t, report.Passed())
+
+	sandbox, _ := server.Sandbox("sandbox-1")
+	assert.Equal(t, string(instruqt.SandboxStateStopped), sandbox.State)
+	assert.Equal(t, "completed", server.ChallengeStatus("user-2", "ch-3"))
+
+	var junit bytes.Buffer
+	require.NoError(t, report.WriteJUnit(&junit))
+	assert.Contains(t, junit.String(), `<testsuites name="instruqt" tests="4" failures="0" errors="1" skipped="0"`)
+}
+
+func TestServer_AnalyzeChallengeAttempts(t *testing.T) {
+	started := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	attempt := func(minutes int, message string) instruqt.ChallengeAttempt {
+		return instruqt.ChallengeAttempt{Message: message, Timestamp: started.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	data := testDataset()
+	data.Progress = map[string]map[string]string{
+		"user-1": {"ch-1": "completed", "ch-2": "started"},
+		"user-2": {"ch-1": "completed", "ch-2": "completed", "ch-3": "unlocked"},
+		"user-3": {"ch-1": "started"},
+	}
+	data.Attempts = map[string]map[string][]instruqt.ChallengeAttempt{
+		"user-1": {
+			"ch-1": {attempt(5, "")},
+			"ch-2": {attempt(10, "pod cilium-x7k2p is not ready"), attempt(12, "pod cilium-x7k2p is not ready")},
+		},
+		"user-2": {
+			"ch-1": {attempt(65, "")},
+			"ch-2": {attempt(70, "expected 3 nodes, got 1"), attempt(76, "")},
+			// Made after the end of the window.
+			"ch-3": {attempt(200, "hubble is not running")},
+		},
+		"user-3": {
+			// Made before the play of the user in the window.
+			"ch-1": {attempt(-60, "cilium is not installed")},
+		},
+	}
+	server := NewServer(data)
+	defer server.Close()
+
+	analytics, err := server.Client().AnalyzeChallengeAttempts("track-1", started, started.Add(150*time.Minute), instruqt.WithBatchSize(4))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, analytics.Players)
+	require.Len(t, analytics.Challenges, 3)
+
+	intro := analytics.Challenges[0]
+	assert.Equal(t, 3, intro.Players)
+	assert.Equal(t, 2, intro.Completed)
+	assert.Equal(t, 2, intro.FirstTrySuccesses)
+	assert.Zero(t, intro.FailedAttempts)
+
+	install := analytics.Challenges[1]
+	assert.Equal(t, "install", install.ChallengeSlug)
+	assert.Equal(t, 2, install.Players)
+	assert.Equal(t, 4, install.Attempts)
+	assert.Equal(t, 3, install.FailedAttempts)
+	assert.Equal(t, 1, install.DropOffs)
+	assert.Equal(t, 6*time.Minute, install.MedianTimeFromFirstCheckToSuccess)
+	require.Len(t, install.FailureMessages, 2)
+	assert.Equal(t, "pod cilium-x<n>k<n>p is not ready", install.FailureMessages[0].Message)
+	assert.Equal(t, "pod cilium-x7k2p is not ready", install.FailureMessages[0].Example)
+	assert.Equal(t, 2, install.FailureMessages[0].Count)
+	assert.Equal(t, "expected <n> nodes, got <n>", install.FailureMessages[1].Message)
+
+	observe := analytics.Challenges[2]
+	assert.Equal(t, 1, observe.Players)
+	assert.Zero(t, observe.Attempts, "Expected checks after the window to be left out")
+	assert.Empty(t, observe.FailureMessages)
+
+	var lookups int
+	for _, r := range server.Requests() {
+		if strings.Contains(r.Query, "challenge(userID:") {
+			lookups++
+		}
+	}
+	assert.Equal(t, 3, lookups, "Expected 9 user challenges to be fetched in batches of 4")
+}
+
+func TestServer_SandboxVariables(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	require.NoError(t, client.SetSandboxVariable("sandbox-1", "server", "TOKEN", "secret"))
+
+	value, err := client.GetSandboxVariable("sandbox-1", "server", "TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+}
+
+func TestServer_NotFound(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+
+	_, err := server.Client().GetTrackById("missing")
+	assert.ErrorIs(t, err, instruqt.ErrNotFound)
+}
+
+func TestServer_UnknownField(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+
+	data, errs := server.execute(Request{Query: "{unknown{id}}"})
+	assert.Nil(t, data)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "GRAPHQL_VALIDATION_FAILED", errs[0].Extensions["code"])
+
+	_, errs = server.execute(Request{Query: "{track(trackID: \"track-1\"){id"})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "GRAPHQL_PARSE_FAILED", errs[0].Extensions["code"])
+}
+
+func TestParseOperation(t *testing.T) {
+	op, err := parseOperation(`mutation ($id:String!){c0: challenge(challengeID: $id, filter: {states: [ACTIVE, "x"], take: 2}){id,track{id}}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mutation", op.kind)
+	require.Len(t, op.selections, 1)
+	sel := op.selections[0]
+	assert.Equal(t, "c0", sel.alias)
+	assert.Equal(t, "challenge", sel.name)
+	assert.Equal(t, map[string]any{
+		"challengeID": "ch-1",
+		"filter":      map[string]any{"states": []any{"ACTIVE", "x"}, "take": 2.0},
+	}, resolveValue(sel.args, map[string]any{"id": "ch-1"}))
+	assert.Equal(t, []selection{{alias: "id", name: "id"}, {alias: "track", name: "track", selections: []selection{{alias: "id", name: "id"}}}}, sel.selections)
+}
+
+func TestServer_TrackMutations(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	created, err := client.CreateTrack(instruqt.TrackInput{Slug: "advanced", Title: "Advanced", Tags: []string{"ebpf"}})
+	require.NoError(t, err)
+	assert.Equal(t, "advanced", created.Slug)
+	assert.Equal(t, []instruqt.TrackTag{{Value: "ebpf"}}, created.TrackTags)
+
+	updated, err := client.UpdateTrack(created.Id, instruqt.TrackInput{Teaser: "Go further"})
+	require.NoError(t, err)
+	assert.Equal(t, "Advanced", updated.Title)
+	assert.Equal(t, "Go further", updated.Teaser)
+
+	_, err = client.SetTrackMaintenance(created.Id, true)
+	require.NoError(t, err)
+	slugs, err := client.GetTracksInMaintenance()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"advanced"}, slugs)
+
+	archived, err := client.ArchiveTrack("track-1")
+	require.NoError(t, err)
+	assert.False(t, archived.DeletedAt.IsZero())
+
+	require.NoError(t, client.DeleteTrack(created.Id))
+	_, err = client.GetTrackById(created.Id)
+	assert.ErrorIs(t, err, instruqt.ErrNotFound)
+}
+
+func TestServer_ChallengeMutations(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	created, err := client.CreateChallenge("track-1", instruqt.ChallengeInput{Slug: "cleanup", Title: "Clean Up", Assignment: "# Done"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, created.Index)
+
+	_, err = client.UpdateChallenge("ch-1", instruqt.ChallengeInput{Title: "Welcome"})
+	require.NoError(t, err)
+	assignment, err := client.GetChallengeWithAssignment(created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "# Done", assignment.Assignment)
+
+	require.NoError(t, client.SetChallengeScript(created.Id, instruqt.ChallengeScript{Host: "server", Action: instruqt.ChallengeScriptCheck, Contents: "exit 0"}))
+	assert.Equal(t, []instruqt.ChallengeScript{{Host: "server", Action: instruqt.ChallengeScriptCheck, Contents: "exit 0"}}, server.ChallengeScripts(created.Id))
+
+	reordered, err := client.ReorderChallenges("track-1", []string{created.Id, "ch-1", "ch-2", "ch-3"})
+	require.NoError(t, err)
+	assert.Equal(t, created.Id, reordered[0].Id)
+	assert.Equal(t, "Welcome", reordered[1].Title)
+
+	require.NoError(t, client.DeleteChallenge("ch-2"))
+	challenges, err := client.GetChallenges("track-1")
+	require.NoError(t, err)
+	assert.Len(t, challenges, 3)
+	assert.Equal(t, 2, challenges[2].Index)
+}
+
+func TestServer_ExportImportTrack(t *testing.T) {
+	staging := testDataset()
+	staging.Maintenance = map[string]bool{"getting-started": true}
+	for i := range staging.Tracks[0].Challenges {
+		staging.Tracks[0].Challenges[i].Assignment = "Assignment of " + staging.Tracks[0].Challenges[i].Slug
+	}
+	stagingServer := NewServer(staging)
+	defer stagingServer.Close()
+
+	production := testDataset()
+	production.TeamSlug = "isovalent-prod"
+	production.Tracks[0].Id = "prod-track"
+	production.Tracks[0].Title = "Outdated"
+	production.Tracks[0].Challenges = []instruqt.Challenge{
+		{Id: "prod-ch-3", Slug: "observe", Index: 0},
+		{Id: "prod-ch-9", Slug: "removed", Index: 1},
+	}
+	productionServer := NewServer(production)
+	defer productionServer.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, stagingServer.Client().ExportTrack("track-1", dir))
+
+	track, err := productionServer.Client().ImportTrack(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod-track", track.Id)
+	assert.Equal(t, "Getting Started", track.Title)
+	require.Len(t, track.Challenges, 3)
+	for i, slug := range []string{"intro", "install", "observe"} {
+		assert.Equal(t, slug, track.Challenges[i].Slug)
+		assert.Equal(t, i, track.Challenges[i].Index)
+	}
+	assert.Equal(t, "prod-ch-3", track.Challenges[2].Id, "Expected existing challenges to be updated in place")
+
+	assignment, err := productionServer.Client().GetChallengeWithAssignment("prod-ch-3")
+	require.NoError(t, err)
+	assert.Equal(t, "Assignment of observe\n", assignment.Assignment)
+
+	slugs, err := productionServer.Client().GetTracksInMaintenance()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"getting-started"}, slugs)
+}
+
+func TestServer_CatalogDiff(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+
+	before, err := client.GetCatalog()
+	require.NoError(t, err)
+	require.Len(t, before.Tracks, 1)
+	assert.Len(t, before.Tracks[0].Challenges, 3)
+	require.Len(t, before.Invites, 1)
+	assert.Equal(t, "track-1", before.Invites[0].Tracks[0].Id)
+
+	_, err = client.UpdateChallenge("ch-1", instruqt.ChallengeInput{Title: "Welcome"})
+	require.NoError(t, err)
+	_, err = client.SetTrackMaintenance("track-1", true)
+	require.NoError(t, err)
+
+	after, err := client.GetCatalog()
+	require.NoError(t, err)
+
+	d := instruqt.Diff(before, after)
+	assert.Equal(t, []instruqt.EntityChange{{
+		Kind: instruqt.ChangeChanged, Id: "track-1", Name: "getting-started",
+		Fields: []instruqt.FieldChange{{Field: "Maintenance", Old: false, New: true}},
+	}}, d.Tracks)
+	assert.Equal(t, []instruqt.EntityChange{{
+		Kind: instruqt.ChangeChanged, Id: "ch-1", Name: "intro", TrackId: "track-1",
+		Fields: []instruqt.FieldChange{{Field: "Title", Old: "Introduction", New: "Welcome"}},
+	}}, d.Challenges)
+	assert.Empty(t, d.Invites)
+}
+
+func TestServer_RecordTrackVersions(t *testing.T) {
+	server := NewServer(testDataset())
+	defer server.Close()
+	client := server.Client()
+	store := instruqt.NewTrackVersionStore()
+
+	recorded, err := client.RecordTrackVersions(store)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Len(t, recorded[0].ChallengeHashes, 3)
+
+	recorded, err = client.RecordTrackVersions(store)
+	require.NoError(t, err)
+	assert.Empty(t, recorded)
+
+	_, err = client.UpdateChallenge("ch-2", instruqt.ChallengeInput{Assignment: "Run `cilium install`."})
+	require.NoError(t, err)
+
+	recorded, err = client.RecordTrackVersions(store)
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, 2, recorded[0].Version)
+	assert.Equal(t, []string{"ch-2"}, recorded[0].ChangedChallenges)
+}
+
+func TestServer_RenderAssignment(t *testing.T) {
+	data := testDataset()
+	data.SandboxVariables = map[string]map[string]map[string]string{
+		"sandbox-1": {"server": {"CLUSTER": "kind-1", "VERSION": "1.16.0"}},
+	}
+	server := NewServer(data)
+	defer server.Close()
+	client := server.Client()
+
+	assignment := "Connect to [[ Instruqt-Var key=\"CLUSTER\" hostname=\"server\" ]].\n\n" +
+		"```bash,run\ncilium install --version [[ Instruqt-Var key=\"VERSION\" hostname=\"server\" ]]\n```\n\n" +
+		"Your token is [[ Instruqt-Var key=\"TOKEN\" hostname=\"server\" ]], on [[ Instruqt-Var key=\"CLUSTER\" hostname=\"server\" ]].\n"
+
+	rendered, err := client.RenderAssignment("sandbox-1", assignment, instruqt.WithBatchSize(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Connect to kind-1.\n\n"+
+		"```bash,run\ncilium install --version 1.16.0\n```\n\n"+
+		"Your token is [[ Instruqt-Var key=\"TOKEN\" hostname=\"server\" ]], on kind-1.\n", rendered.Markdown)
+	require.Len(t, rendered.Missing, 1)
+	assert.EqualError(t, rendered.Err(), "missing sandbox variables: server/TOKEN (line 7)")
+
+	var getVariables int
+	for _, r := range server.Requests() {
+		if strings.Contains(r.Query, "getSandboxVariable") {
+			getVariables++
+		}
+	}
+	assert.Equal(t, 2, getVariables, "Expected 3 distinct variables to be fetched in batches of 2")
+}
+
+func TestCloneDataset(t *testing.T) {
+	data := Dataset{
+		Reviews: map[string][]instruqt.Review{"track-1": {{}}},
+		Scripts: map[string][]instruqt.ChallengeScript{"ch-1": {{Host: "server"}}},
+	}
+
+	clone := cloneDataset(data)
+	clone.Reviews["track-1"][0].Id = "review-1"
+	clone.Scripts["ch-1"][0].Host = "workstation"
+
+	assert.Empty(t, data.Reviews["track-1"][0].Id, "Expected reviews not to be shared with the caller")
+	assert.Equal(t, "server", data.Scripts["ch-1"][0].Host)
+}